@@ -0,0 +1,89 @@
+/* Every file on disk was written by something that picked a line-ending
+convention, and silently rewriting it to Unix style on save is exactly the
+kind of surprise diff that makes Windows-authored files unreviewable. This
+package detects what a file is actually using so it can be preserved. */
+
+package buffer
+
+import "strings"
+
+// LineEnding identifies a file's line terminator convention
+type LineEnding string
+
+const (
+	LF    LineEnding = "LF"
+	CRLF  LineEnding = "CRLF"
+	CR    LineEnding = "CR"
+	Mixed LineEnding = "Mixed"
+)
+
+// sampleSize is how much of the content DetectLineEnding inspects; reading
+// the whole file isn't necessary to find the dominant convention.
+const sampleSize = 8 * 1024
+
+// DetectLineEnding counts CRLF/LF/CR occurrences in the first ~8KB of
+// content and returns whichever style holds at least 90% of the
+// terminators found, or Mixed if no single style reaches that majority.
+// Content with no line terminators at all is treated as LF.
+func DetectLineEnding(content string) LineEnding {
+	sample := content
+	if len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+
+	var crlf, lf, cr int
+	b := []byte(sample)
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '\r':
+			if i+1 < len(b) && b[i+1] == '\n' {
+				crlf++
+				i++
+			} else {
+				cr++
+			}
+		case '\n':
+			lf++
+		}
+	}
+
+	total := crlf + lf + cr
+	if total == 0 {
+		return LF
+	}
+
+	best, bestCount := LF, lf
+	if crlf > bestCount {
+		best, bestCount = CRLF, crlf
+	}
+	if cr > bestCount {
+		best, bestCount = CR, cr
+	}
+
+	if float64(bestCount)/float64(total) >= 0.9 {
+		return best
+	}
+	return Mixed
+}
+
+// Normalize detects content's line ending and returns it rewritten to use
+// plain \n throughout, for internal storage.
+func Normalize(content string) (normalized string, detected LineEnding) {
+	detected = DetectLineEnding(content)
+	normalized = strings.ReplaceAll(content, "\r\n", "\n")
+	normalized = strings.ReplaceAll(normalized, "\r", "\n")
+	return normalized, detected
+}
+
+// Emit re-applies le to \n-normalized content, so saving a buffer that was
+// loaded as CRLF writes CRLF back out instead of silently converting it.
+func Emit(content string, le LineEnding) string {
+	switch le {
+	case CRLF:
+		return strings.ReplaceAll(content, "\n", "\r\n")
+	case CR:
+		return strings.ReplaceAll(content, "\n", "\r")
+	default:
+		return content
+	}
+}
@@ -0,0 +1,113 @@
+/*
+history persists the user's command/search history across runs, the way
+go-readline-ny's simplehistory does: a flat, newline-delimited file of
+past entries, most recent last, with consecutive duplicates collapsed so
+repeatedly hitting the same command doesn't spam the list.
+*/
+package history
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultMaxEntries bounds how many entries Add keeps before trimming the
+// oldest ones off the front.
+const DefaultMaxEntries = 1000
+
+// History is an ordered, deduplicated-by-run list of past entries backed
+// by a file on disk.
+type History struct {
+	Path string // file entries are Load()ed from / Save()d to
+	Max  int    // trims to the Max most recent entries; 0 means unlimited
+
+	entries []string
+}
+
+// New creates a History backed by path, capped at max entries. Load must
+// be called separately to populate it from disk.
+func New(path string, max int) *History {
+	return &History{Path: path, Max: max}
+}
+
+// DefaultPath returns the per-user history file location,
+// ~/.config/letsgo-editor/history.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error resolving home directory: %v", err)
+	}
+	return filepath.Join(home, ".config", "letsgo-editor", "history"), nil
+}
+
+// Add appends entry, skipping empty entries and ones identical to the
+// immediately preceding one, then trims to Max if set.
+func (h *History) Add(entry string) {
+	if entry == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == entry {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if h.Max > 0 && len(h.entries) > h.Max {
+		h.entries = h.entries[len(h.entries)-h.Max:]
+	}
+}
+
+// At returns the entry at index i (0 is the oldest entry), or "" if i is
+// out of range.
+func (h *History) At(i int) string {
+	if i < 0 || i >= len(h.entries) {
+		return ""
+	}
+	return h.entries[i]
+}
+
+// Len returns the number of entries currently held.
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// Load replaces the in-memory entries with the contents of Path, one
+// entry per line. A missing file is not an error; it just leaves History
+// empty, the way a first run has no history yet.
+func (h *History) Load() error {
+	f, err := os.Open(h.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading history file: %v", err)
+	}
+	defer f.Close()
+
+	h.entries = nil
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// Save writes the current entries to Path, one per line, creating the
+// parent directory if needed.
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0755); err != nil {
+		return fmt.Errorf("error creating history directory: %v", err)
+	}
+
+	f, err := os.Create(h.Path)
+	if err != nil {
+		return fmt.Errorf("error writing history file: %v", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, entry := range h.entries {
+		fmt.Fprintln(w, entry)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,126 @@
+package history
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// LineEditor reads one submitted line at a time from Reader (normally
+// stdin), offering readline-style history recall in place of real arrow
+// keys: this package has no raw terminal access of its own, so "up"/"down"
+// typed as a whole line stand in for the Up/Down arrows, and "ctrl+r"
+// stands in for readline's reverse-incremental search - the same
+// typed-token convention run.go's other line-buffered prompts
+// (runFindBar, runHelpPane) already use for Esc/Up/Down.
+type LineEditor struct {
+	Reader  *bufio.Reader
+	History *History
+
+	// pos is how many steps back from the newest entry the last "up"/
+	// "down" landed on; -1 means the user is on a fresh line, not
+	// recalling anything.
+	pos int
+}
+
+// NewLineEditor creates a LineEditor reading from r and recalling
+// through h.
+func NewLineEditor(r *bufio.Reader, h *History) *LineEditor {
+	return &LineEditor{Reader: r, History: h, pos: -1}
+}
+
+// ReadLine prints prompt and returns the next submitted line, resolving
+// any "up"/"down"/"ctrl+r" recall first. The returned line is not added to
+// History - callers decide what's worth persisting (see
+// EditorState.HandleInput and runFindBar).
+func (le *LineEditor) ReadLine(prompt string) string {
+	le.pos = -1
+	for {
+		fmt.Print(prompt)
+		raw, _ := le.Reader.ReadString('\n')
+		line := strings.TrimRight(raw, "\n")
+
+		switch line {
+		case "up":
+			if le.pos+1 < le.History.Len() {
+				le.pos++
+			}
+			fmt.Println(le.recalled())
+		case "down":
+			if le.pos > 0 {
+				le.pos--
+			} else {
+				le.pos = -1
+			}
+			fmt.Println(le.recalled())
+		case "ctrl+r":
+			if found, ok := le.reverseSearch(); ok {
+				return found
+			}
+		default:
+			return line
+		}
+	}
+}
+
+// recalled returns the history entry le.pos steps back from the newest one
+func (le *LineEditor) recalled() string {
+	if le.pos < 0 {
+		return ""
+	}
+	return le.History.At(le.History.Len() - 1 - le.pos)
+}
+
+// reverseSearch drives readline's Ctrl+R: each line narrows the search
+// query and live-filters History for the most recent entry containing it
+// as a substring, scanning backward from the end. "up" steps to the next
+// older match, blank accepts the current match, "esc" cancels back to
+// ReadLine's normal prompt.
+func (le *LineEditor) reverseSearch() (string, bool) {
+	query := ""
+	searchFrom := le.History.Len() - 1
+
+	for {
+		idx := le.searchBackward(query, searchFrom)
+		match := ""
+		if idx >= 0 {
+			match = le.History.At(idx)
+		}
+		fmt.Printf("(reverse-i-search)`%s': %s\n", query, match)
+
+		raw, _ := le.Reader.ReadString('\n')
+		line := strings.TrimRight(raw, "\n")
+
+		switch line {
+		case "esc":
+			return "", false
+		case "":
+			return match, idx >= 0
+		case "up":
+			if idx > 0 {
+				searchFrom = idx - 1
+			}
+		default:
+			query = line
+			searchFrom = le.History.Len() - 1
+		}
+	}
+}
+
+// searchBackward scans History from startIdx down to 0 for the most
+// recent entry containing query as a substring, or -1 if none matches. An
+// empty query matches whatever entry startIdx points at.
+func (le *LineEditor) searchBackward(query string, startIdx int) int {
+	if query == "" {
+		if startIdx >= 0 && startIdx < le.History.Len() {
+			return startIdx
+		}
+		return -1
+	}
+	for i := startIdx; i >= 0; i-- {
+		if strings.Contains(le.History.At(i), query) {
+			return i
+		}
+	}
+	return -1
+}
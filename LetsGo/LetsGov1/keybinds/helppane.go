@@ -0,0 +1,133 @@
+/* The keybinding table is otherwise invisible to the user unless they read
+the keybinds file or call ListKeybindings on stdout. HelpPane turns it into
+a searchable, filterable list: the user types in a search box and the table
+narrows to bindings whose action name or key fuzzy-matches what was typed,
+the way joshuto's keyb help overlay works. Bound to the "show_help" action
+on F1 by default. */
+
+package keybinds
+
+import "sort"
+
+// HelpPane is a searchable, filterable view over a KeybindManager's
+// bindings. It holds no reference to the manager so it can be reused/reset
+// freely; call Refresh whenever the underlying bindings change.
+type HelpPane struct {
+	Query   string
+	all     []Keybinding
+	Visible bool
+}
+
+// NewHelpPane builds a HelpPane over the given manager's current bindings
+func NewHelpPane(km *KeybindManager) *HelpPane {
+	hp := &HelpPane{}
+	hp.Refresh(km)
+	return hp
+}
+
+// Refresh recopies the manager's bindings so later filtering reflects
+// rebinding/macro changes made since the pane was created.
+func (hp *HelpPane) Refresh(km *KeybindManager) {
+	hp.all = append([]Keybinding{}, km.Bindings...)
+}
+
+// helpMatch pairs a binding with the best score its action/key earned
+// against the current query, for sorting.
+type helpMatch struct {
+	binding Keybinding
+	score   int
+}
+
+// Filter fuzzy-matches hp.Query against every binding's action name and
+// key, keeping whichever of the two scores higher, and returns the
+// surviving bindings ranked best match first. An empty query returns every
+// binding in its original order.
+func (hp *HelpPane) Filter() []Keybinding {
+	if hp.Query == "" {
+		return append([]Keybinding{}, hp.all...)
+	}
+
+	var matches []helpMatch
+	for _, b := range hp.all {
+		actionScore, _, actionOK := FuzzyScore(hp.Query, b.Action)
+		keyScore, _, keyOK := FuzzyScore(hp.Query, b.Key)
+
+		if !actionOK && !keyOK {
+			continue
+		}
+
+		score := actionScore
+		if keyOK && keyScore > score {
+			score = keyScore
+		}
+
+		matches = append(matches, helpMatch{binding: b, score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	results := make([]Keybinding, len(matches))
+	for i, m := range matches {
+		results[i] = m.binding
+	}
+	return results
+}
+
+// FuzzyScore performs a sequential-character subsequence match of pattern
+// against candidate (case-insensitive), the same scoring shape fzf/Sublime
+// style fuzzy finders use: it rewards consecutive matches and matches that
+// start a word, so a pattern like "sv" scores "save_file" above a random
+// string that merely contains an 's' and a 'v' far apart. Returns whether
+// every rune of pattern was found in order.
+func FuzzyScore(pattern, candidate string) (score int, matchedIndices []int, ok bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+
+	p := []rune(toLower(pattern))
+	c := []rune(toLower(candidate))
+
+	pi := 0
+	consecutive := 0
+	for ci := 0; ci < len(c) && pi < len(p); ci++ {
+		if c[ci] != p[pi] {
+			consecutive = 0
+			continue
+		}
+
+		points := 1
+		if consecutive > 0 {
+			points += consecutive * 2
+		}
+		if ci == 0 || isWordBoundary(c[ci-1]) {
+			points += 3
+		}
+
+		score += points
+		matchedIndices = append(matchedIndices, ci)
+		consecutive++
+		pi++
+	}
+
+	return score, matchedIndices, pi == len(p)
+}
+
+// isWordBoundary reports whether r separates words (so the rune after it
+// starts a new one) for the word-boundary-start fuzzy match bonus.
+func isWordBoundary(r rune) bool {
+	return r == '_' || r == '-' || r == ' ' || r == '/' || r == '.'
+}
+
+// toLower lowercases ASCII letters without pulling in unicode/strings just
+// for this; keybinding actions and keys are always ASCII.
+func toLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,146 @@
+/* Bindings are no longer limited to a single key: a Key string can be a
+space-separated sequence like "Ctrl+K Ctrl+S" or "Space f o", the way
+tmux-style prefixes work in keyb. KeyResolver is the state machine that
+walks the bindings a key at a time, tracking whichever prefix is currently
+pending so MainUI can show it in the status bar. */
+
+package keybinds
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultChordTimeout is how long the resolver waits for the next key in a
+// chord before giving up and resetting to the root, matching tmux's
+// prefix-key timeout behavior.
+const DefaultChordTimeout = time.Second
+
+// keyNode is one token of a chord trie. A node with no children is terminal
+// and holds the action it resolves to.
+type keyNode struct {
+	children map[string]*keyNode
+	action   string
+}
+
+func newKeyNode() *keyNode {
+	return &keyNode{children: map[string]*keyNode{}}
+}
+
+// KeyResolver walks a trie built from every binding's (possibly
+// multi-token) Key, resolving to an action on a full match and resetting
+// on a mismatched key or on timeout.
+type KeyResolver struct {
+	root    *keyNode
+	current *keyNode
+	pending []string // tokens fed so far for the in-progress chord
+	Timeout time.Duration
+	lastFed time.Time
+}
+
+// NewKeyResolver builds a resolver trie from the given bindings
+func NewKeyResolver(bindings []Keybinding) *KeyResolver {
+	r := &KeyResolver{root: newKeyNode(), Timeout: DefaultChordTimeout}
+	r.current = r.root
+	for _, b := range bindings {
+		r.add(b)
+	}
+	return r
+}
+
+// add inserts a binding's space-separated key tokens into the trie
+func (r *KeyResolver) add(b Keybinding) {
+	node := r.root
+	for _, tok := range strings.Fields(b.Key) {
+		child, ok := node.children[tok]
+		if !ok {
+			child = newKeyNode()
+			node.children[tok] = child
+		}
+		node = child
+	}
+	node.action = b.Action
+}
+
+// Feed advances the resolver by one key token (e.g. "Ctrl+X"). It returns:
+//   - action, pending=false, reset=false  when that key completes a binding
+//   - "", pending=true, reset=false       while a prefix matched but isn't
+//     complete yet (the caller should keep reading keys)
+//   - "", pending=false, reset=true       when the key doesn't continue any
+//     pending chord (a stale chord timed out, or the key is simply unbound);
+//     the resolver has already reset to root, so callers that want the key
+//     itself re-tried as the start of a new chord should call Feed again
+func (r *KeyResolver) Feed(key string) (action string, pending bool, reset bool) {
+	now := time.Now()
+	wasPending := r.current != r.root
+	if wasPending && now.Sub(r.lastFed) > r.Timeout {
+		r.current = r.root
+		wasPending = false
+	}
+	r.lastFed = now
+
+	child, ok := r.current.children[key]
+	if !ok {
+		r.current = r.root
+		r.pending = nil
+		return "", false, wasPending
+	}
+
+	r.pending = append(append([]string{}, r.pending...), key)
+	r.current = child
+
+	if len(child.children) == 0 {
+		r.current = r.root
+		r.pending = nil
+		return child.action, false, false
+	}
+
+	return "", true, false
+}
+
+// Pending returns the space-joined keys fed so far for the chord in
+// progress, for display in a status bar ("Ctrl+X ...").
+func (r *KeyResolver) Pending() string {
+	return strings.Join(r.pending, " ")
+}
+
+// Reset abandons any in-progress chord
+func (r *KeyResolver) Reset() {
+	r.current = r.root
+	r.pending = nil
+}
+
+// Add inserts a single binding into the trie without rebuilding the whole
+// resolver, so a chord registered at runtime (see KeybindManager.Bind)
+// takes effect immediately.
+func (r *KeyResolver) Add(b Keybinding) {
+	r.add(b)
+}
+
+// Completion is one key reachable from wherever the resolver currently
+// sits: Key is the next token to press, Action is what it resolves to if
+// that's the final token of its binding, or "" if pressing it continues a
+// longer chord.
+type Completion struct {
+	Key    string
+	Action string
+}
+
+// Completions lists every immediate next key from the resolver's current
+// position (the root if no chord is pending), sorted by key for a stable
+// hint bar. A "Ctrl+X" prefix bound to "s"->save_file, "o"->open_file,
+// "w"->close_file reports three completions once "Ctrl+X" has been fed.
+func (r *KeyResolver) Completions() []Completion {
+	keys := make([]string, 0, len(r.current.children))
+	for k := range r.current.children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	completions := make([]Completion, len(keys))
+	for i, k := range keys {
+		completions[i] = Completion{Key: k, Action: r.current.children[k].action}
+	}
+	return completions
+}
@@ -0,0 +1,149 @@
+/*
+Macro recording/playback, giving KeybindManager the same programmable
+editing loop micro exposes via ToggleMacro/PlayMacro: every action
+dispatched while recording (menu, toolbar, or key) is appended to a
+buffer, and playback re-dispatches each one through the caller's own
+action handler, so a macro replay is indistinguishable from the user
+repeating those keystrokes by hand.
+*/
+
+package keybinds
+
+import "strings"
+
+// macroActionPrefix marks an action string as "replay this macro" rather
+// than a regular editor action, so a recorded macro can be bound to a key
+// like any other action (see MacroAction/MacroName).
+const macroActionPrefix = "@"
+
+// Action is one recorded step of a macro: the dispatched action string,
+// plus an optional argument for actions that carry one (unused by any
+// current action, but here so a future parameterized action doesn't need
+// a format change).
+type Action struct {
+	Name string `json:"name"`
+	Arg  string `json:"arg,omitempty"`
+}
+
+// Macro is a named, persisted sequence of recorded actions.
+type Macro struct {
+	Name    string   `json:"name"`
+	Actions []Action `json:"actions"`
+}
+
+// MacroAction returns the bindable action string for a named macro, e.g.
+// AddKeybinding(MacroAction("refactor"), "Ctrl+Alt+1") replays it on that
+// key.
+func MacroAction(name string) string {
+	return macroActionPrefix + name
+}
+
+// MacroName reports the macro name encoded in a bindable action string by
+// MacroAction, and whether action was actually a macro reference.
+func MacroName(action string) (string, bool) {
+	if !strings.HasPrefix(action, macroActionPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(action, macroActionPrefix), true
+}
+
+// StartRecording begins capturing every action subsequently passed to
+// Record into a new macro named name. It's a no-op while already
+// recording, so a macro can't be nested into its own recording.
+func (km *KeybindManager) StartRecording(name string) {
+	if km.recording {
+		return
+	}
+	km.recording = true
+	km.recordingName = name
+	km.currentMacro = nil
+}
+
+// StopRecording ends the capture started by StartRecording, saving the
+// recorded steps as a Macro (replacing any existing macro of the same
+// name) and into the "last macro" slot Play uses for PlayLast. It's a
+// no-op if nothing is being recorded.
+func (km *KeybindManager) StopRecording() {
+	if !km.recording {
+		return
+	}
+	km.recording = false
+
+	name := km.recordingName
+	recorded := km.currentMacro
+	km.recordingName = ""
+	km.currentMacro = nil
+
+	km.setMacro(Macro{Name: name, Actions: recorded})
+	km.lastMacro = name
+}
+
+// Recording reports whether a macro is currently being captured. Callers
+// that dispatch an action themselves (rather than through Record) can use
+// this to decide whether to also call Record.
+func (km *KeybindManager) Recording() bool {
+	return km.recording
+}
+
+// Record appends action to the macro currently being captured. It's a
+// no-op when not recording, so callers can call it unconditionally for
+// every action they dispatch instead of guarding on Recording() first.
+func (km *KeybindManager) Record(action Action) {
+	if !km.recording {
+		return
+	}
+	km.currentMacro = append(km.currentMacro, action)
+}
+
+// LastMacro returns the name of the most recently recorded or played
+// macro, or "" if none has been yet, for PlayLast-style bindings.
+func (km *KeybindManager) LastMacro() string {
+	return km.lastMacro
+}
+
+// FindMacro looks up a recorded macro by name
+func (km *KeybindManager) FindMacro(name string) *Macro {
+	for i := range km.Macros {
+		if km.Macros[i].Name == name {
+			return &km.Macros[i]
+		}
+	}
+	return nil
+}
+
+// setMacro adds a macro, or replaces the existing one with the same name
+func (km *KeybindManager) setMacro(m Macro) {
+	for i := range km.Macros {
+		if km.Macros[i].Name == m.Name {
+			km.Macros[i] = m
+			return
+		}
+	}
+	km.Macros = append(km.Macros, m)
+}
+
+// Play replays the named macro by calling dispatch once per recorded
+// action, in order - dispatch should be the same action handler live
+// keystrokes use (e.g. EditorState.HandleInput), so the replay looks
+// exactly like the user repeating those keystrokes. It returns false if no
+// macro is recorded under name.
+func (km *KeybindManager) Play(name string, dispatch func(Action)) bool {
+	macro := km.FindMacro(name)
+	if macro == nil {
+		return false
+	}
+	km.lastMacro = name
+	for _, action := range macro.Actions {
+		dispatch(action)
+	}
+	return true
+}
+
+// PlayLast replays the "last macro" slot - whichever macro was most
+// recently recorded or played - without the caller needing to name it.
+func (km *KeybindManager) PlayLast(dispatch func(Action)) bool {
+	if km.lastMacro == "" {
+		return false
+	}
+	return km.Play(km.lastMacro, dispatch)
+}
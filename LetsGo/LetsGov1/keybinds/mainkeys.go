@@ -17,6 +17,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // Keybinding represents a mapping from an action to a key
@@ -30,6 +32,16 @@ type Keybinding struct {
 //
 type KeybindManager struct {
 	Bindings []Keybinding `json:"bindings"`
+
+	// Macros holds every recorded macro, persisted alongside Bindings so
+	// a macro named e.g. "refactor" survives restarts and can be bound to
+	// a key as the action MacroAction("refactor") (see macro.go).
+	Macros []Macro `json:"macros,omitempty"`
+
+	recording     bool
+	recordingName string
+	currentMacro  []Action
+	lastMacro     string
 }
 
 // LoadKeybindings loads keybindings from a file
@@ -53,13 +65,18 @@ func (km *KeybindManager) LoadKeybindings(filename string) error {
 	return nil
 }
 
-// SaveKeybindings saves keybindings to a file
+// SaveKeybindings saves keybindings to a file, creating its parent
+// directory if needed.
 func (km *KeybindManager) SaveKeybindings(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("error creating keybindings directory: %v", err)
+	}
+
 	data, err := json.MarshalIndent(km, "", "  ")
 	if err != nil {
 		return fmt.Errorf("error marshaling keybindings: %v", err)
 	}
-	
+
 	err = ioutil.WriteFile(filename, data, 0644)
 	if err != nil {
 		return fmt.Errorf("error writing keybindings file: %v", err)
@@ -85,6 +102,9 @@ func (km *KeybindManager) ResetToDefault() {
 		{Action: "paste", Key: "Ctrl+V"},
 		{Action: "select_all", Key: "Ctrl+A"},
 		{Action: "search", Key: "Ctrl+F"},
+		{Action: "show_help", Key: "F1"},
+		{Action: "set_line_ending_lf", Key: "Ctrl+Alt+L"},
+		{Action: "set_line_ending_crlf", Key: "Ctrl+Alt+C"},
 		{Action: "replace", Key: "Ctrl+H"},
 		{Action: "font_increase", Key: "Ctrl++"},
 		{Action: "font_decrease", Key: "Ctrl+-"},
@@ -100,6 +120,25 @@ func (km *KeybindManager) ResetToDefault() {
 		{Action: "prev_line", Key: "Up"},
 		{Action: "next_char", Key: "Right"},
 		{Action: "prev_char", Key: "Left"},
+		{Action: "select_left", Key: "Shift+Left"},
+		{Action: "select_right", Key: "Shift+Right"},
+		{Action: "select_up", Key: "Shift+Up"},
+		{Action: "select_down", Key: "Shift+Down"},
+		{Action: "select_word_left", Key: "Ctrl+Shift+Left"},
+		{Action: "select_word_right", Key: "Ctrl+Shift+Right"},
+		{Action: "file_palette", Key: "Ctrl+P"},
+		{Action: "command_palette", Key: "Ctrl+Shift+P"},
+		{Action: "toggle_macro_recording", Key: "Ctrl+Alt+R"},
+		{Action: "play_last_macro", Key: "Ctrl+Alt+P"},
+		{Action: "open_settings", Key: "Ctrl+Alt+S"},
+
+		// "Ctrl+G" is an Emacs-style leader left free for chords: it isn't
+		// bound on its own, so these add second ways to reach actions that
+		// already have a plain-key binding above, demonstrating the hint
+		// bar without touching any existing shortcut.
+		{Action: "save_file", Key: "Ctrl+G s"},
+		{Action: "open_file", Key: "Ctrl+G o"},
+		{Action: "close_file", Key: "Ctrl+G w"},
 	}
 	fmt.Println("Keybindings reset to default")
 }
@@ -124,6 +163,16 @@ func (km *KeybindManager) FindAction(key string) string {
 	return ""
 }
 
+// Bind adds or updates a keybinding given as (key, action) - the same as
+// AddKeybinding with its arguments swapped, since a chord reads better
+// key-first: Bind("Ctrl+X Ctrl+S", "save_file"). Key may be a single token
+// or a space-separated sequence like "Ctrl+G s"; either way it's stored
+// and serialized exactly as given, since Keybinding.Key is already a plain
+// string and KeyResolver already splits it on whitespace.
+func (km *KeybindManager) Bind(key, action string) {
+	km.AddKeybinding(action, key)
+}
+
 // AddKeybinding adds or updates a keybinding
 func (km *KeybindManager) AddKeybinding(action, key string) {
 	// Check if action already exists
@@ -162,20 +211,61 @@ func (km *KeybindManager) ListKeybindings() {
 	fmt.Printf("Total: %d keybindings\n\n", len(km.Bindings))
 }
 
-// ValidateKeybindings checks for duplicate keys
+// ValidateKeybindings checks for duplicate keys and prefix conflicts (one
+// binding's key sequence being a strict prefix of another's, e.g. "Ctrl+X"
+// bound on its own as well as as the first token of "Ctrl+X Ctrl+S" -
+// whichever fires first would swallow the other).
 func (km *KeybindManager) ValidateKeybindings() []string {
 	keyMap := make(map[string][]string)
 	var conflicts []string
-	
+
 	for _, binding := range km.Bindings {
 		keyMap[binding.Key] = append(keyMap[binding.Key], binding.Action)
 	}
-	
+
 	for key, actions := range keyMap {
 		if len(actions) > 1 {
 			conflicts = append(conflicts, fmt.Sprintf("Key '%s' is bound to multiple actions: %v", key, actions))
 		}
 	}
-	
+
+	conflicts = append(conflicts, km.validatePrefixConflicts()...)
+
 	return conflicts
 }
+
+// validatePrefixConflicts flags any binding whose key sequence is a strict,
+// token-wise prefix of another binding's key sequence.
+func (km *KeybindManager) validatePrefixConflicts() []string {
+	var conflicts []string
+
+	tokenized := make([][]string, len(km.Bindings))
+	for i, b := range km.Bindings {
+		tokenized[i] = strings.Fields(b.Key)
+	}
+
+	for i, short := range tokenized {
+		for j, long := range tokenized {
+			if i == j || len(short) >= len(long) {
+				continue
+			}
+			if isTokenPrefix(short, long) {
+				conflicts = append(conflicts, fmt.Sprintf(
+					"Key '%s' (action %q) is a prefix of key '%s' (action %q) and will never be reached as a chord",
+					km.Bindings[i].Key, km.Bindings[i].Action, km.Bindings[j].Key, km.Bindings[j].Action))
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// isTokenPrefix reports whether short is a prefix of long, token by token
+func isTokenPrefix(short, long []string) bool {
+	for i, tok := range short {
+		if long[i] != tok {
+			return false
+		}
+	}
+	return true
+}
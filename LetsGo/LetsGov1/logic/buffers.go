@@ -6,23 +6,243 @@ and all the splitscreens instances will be shown in the mainui.go add menu bar n
 
 package logic
 
-// Buffer represents an open file/tab
-//
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"letsgo-editor/buffer"
+	"letsgo-editor/syntax"
+)
+
+// coalesceWindow bounds how close together two insertions at adjoining
+// offsets must land in time to be folded into the same undo record, so
+// typing a word is one undo instead of one undo per keystroke.
+const coalesceWindow = 750 * time.Millisecond
+
+// editRecord is a reversible edit: either Inserted or Deleted is set (an
+// Insert/Delete call never does both), so undo/redo can replay it in
+// reverse without keeping a full document snapshot.
+type editRecord struct {
+	Offset    int
+	Inserted  string
+	Deleted   string
+	Timestamp time.Time
+
+	// Group is nonzero while the edit was made inside a
+	// BeginUndoGroup/EndUndoGroup bracket; Undo/Redo pop every
+	// consecutive record sharing a Group in one call instead of one
+	// record at a time, so e.g. a macro replay undoes as a single step.
+	Group int
+}
+
+// Buffer represents an open file/tab. Its content is a rope rather than
+// a plain string, and Insert/Delete keep a per-buffer undo/redo history
+// so each open file undoes independently of the others.
 type Buffer struct {
-	Name    string
-	Content string
-	Cursor  int
+	Name             string
+	Cursor           Cursor
+	LineEnding       buffer.LineEnding // original terminator style, preserved on save
+	DetectedEncoding string            // best-guess text encoding, e.g. "utf-8"
+
+	content  *rope
+	undo     []editRecord
+	redo     []editRecord
+	lang     *syntax.Language
+	group    int // current undo group id; 0 means edits aren't grouped
+	groupSeq int // monotonic source of new group ids
+}
+
+// BeginUndoGroup starts tagging subsequent edits with a new undo group, so
+// a single Undo/Redo call reverts/reapplies all of them together instead
+// of one at a time. Used to bracket a macro replay (see
+// keybinds.KeybindManager.Play) so it undoes as one step.
+func (b *Buffer) BeginUndoGroup() {
+	b.groupSeq++
+	b.group = b.groupSeq
+}
+
+// EndUndoGroup stops tagging new edits with the current undo group
+func (b *Buffer) EndUndoGroup() {
+	b.group = 0
+}
+
+// SetLanguage selects the syntax used by HighlightLine, e.g. from a
+// syntax.Registry lookup on the buffer's file extension
+func (b *Buffer) SetLanguage(lang *syntax.Language) {
+	b.lang = lang
+}
+
+// HighlightLine returns the styled spans for line n, recomputed from the
+// buffer's current content so edits are reflected on the next render
+// without any separate invalidation step.
+func (b *Buffer) HighlightLine(n int) []syntax.Span {
+	return b.lang.Highlight(b.LineAt(n))
+}
+
+// NewBuffer creates a buffer with the given initial content
+func NewBuffer(name, content string, le buffer.LineEnding) *Buffer {
+	return &Buffer{
+		Name:             name,
+		LineEnding:       le,
+		DetectedEncoding: "utf-8",
+		content:          newRope(content),
+	}
+}
+
+// String returns the full buffer content
+func (b *Buffer) String() string {
+	return b.content.String()
+}
+
+// Slice returns the runes in [start,end) of the buffer
+func (b *Buffer) Slice(start, end int) string {
+	return b.content.Slice(start, end)
+}
+
+// LineCount returns the number of lines in the buffer
+func (b *Buffer) LineCount() int {
+	content := b.content.String()
+	if content == "" {
+		return 1
+	}
+	return strings.Count(content, "\n") + 1
+}
+
+// LineAt returns the text of line n (0-indexed), or "" if n is out of range
+func (b *Buffer) LineAt(n int) string {
+	lines := strings.Split(b.content.String(), "\n")
+	if n < 0 || n >= len(lines) {
+		return ""
+	}
+	return lines[n]
+}
+
+// Insert inserts text at rune offset pos and records a reversible edit,
+// coalescing into the previous record when it's an adjoining insertion
+// that landed within coalesceWindow of it.
+func (b *Buffer) Insert(pos int, text string) {
+	b.content = b.content.Insert(pos, text)
+	b.redo = nil
+	b.pushEdit(editRecord{Offset: pos, Inserted: text, Timestamp: time.Now(), Group: b.group})
+}
+
+// Delete removes the runes in [start,end) and records a reversible edit
+func (b *Buffer) Delete(start, end int) {
+	deleted := b.content.Slice(start, end)
+	b.content = b.content.Delete(start, end)
+	b.redo = nil
+	b.pushEdit(editRecord{Offset: start, Deleted: deleted, Timestamp: time.Now(), Group: b.group})
+}
+
+// pushEdit appends rec to the undo stack, folding it into the previous
+// record when both are plain insertions that abut in place, in time, and
+// in the same undo group.
+func (b *Buffer) pushEdit(rec editRecord) {
+	if n := len(b.undo); n > 0 {
+		last := &b.undo[n-1]
+		if last.Deleted == "" && rec.Deleted == "" && last.Group == rec.Group &&
+			rec.Offset == last.Offset+utf8.RuneCountInString(last.Inserted) &&
+			rec.Timestamp.Sub(last.Timestamp) < coalesceWindow {
+			last.Inserted += rec.Inserted
+			last.Timestamp = rec.Timestamp
+			return
+		}
+	}
+	b.undo = append(b.undo, rec)
+}
+
+// revertEdit reverses a single edit record in place
+func (b *Buffer) revertEdit(rec editRecord) {
+	if rec.Inserted != "" {
+		n := utf8.RuneCountInString(rec.Inserted)
+		b.content = b.content.Delete(rec.Offset, rec.Offset+n)
+	}
+	if rec.Deleted != "" {
+		b.content = b.content.Insert(rec.Offset, rec.Deleted)
+	}
+}
+
+// reapplyEdit replays a single edit record forward in place
+func (b *Buffer) reapplyEdit(rec editRecord) {
+	if rec.Deleted != "" {
+		n := utf8.RuneCountInString(rec.Deleted)
+		b.content = b.content.Delete(rec.Offset, rec.Offset+n)
+	}
+	if rec.Inserted != "" {
+		b.content = b.content.Insert(rec.Offset, rec.Inserted)
+	}
 }
 
-// BuffersManager manages all open buffers/tabs
-//
+// Undo reverts the most recent edit record, returning false if there's
+// nothing to undo. When that record belongs to an undo group (see
+// BeginUndoGroup) every other record in the same group is reverted along
+// with it, so the whole group undoes in one call.
+func (b *Buffer) Undo() bool {
+	if len(b.undo) == 0 {
+		return false
+	}
+	group := b.undo[len(b.undo)-1].Group
+	for {
+		n := len(b.undo)
+		if n == 0 || b.undo[n-1].Group != group {
+			break
+		}
+		rec := b.undo[n-1]
+		b.undo = b.undo[:n-1]
+		b.revertEdit(rec)
+		b.redo = append(b.redo, rec)
+		if group == 0 {
+			break
+		}
+	}
+	return true
+}
+
+// Redo reapplies the most recently undone edit record, returning false if
+// there's nothing to redo, grouping the same way Undo does.
+func (b *Buffer) Redo() bool {
+	if len(b.redo) == 0 {
+		return false
+	}
+	group := b.redo[len(b.redo)-1].Group
+	for {
+		n := len(b.redo)
+		if n == 0 || b.redo[n-1].Group != group {
+			break
+		}
+		rec := b.redo[n-1]
+		b.redo = b.redo[:n-1]
+		b.reapplyEdit(rec)
+		b.undo = append(b.undo, rec)
+		if group == 0 {
+			break
+		}
+	}
+	return true
+}
+
+// BuffersManager manages all open buffers/tabs, arranged in an optional
+// tmux/Emacs-style split tree: Root is nil until the first split is made,
+// at which point the editor is still showing one Pane (the same single
+// document ActiveBuffer always pointed at), just through the tree instead
+// of implicitly.
 type BuffersManager struct {
-	Buffers      []Buffer
-	ActiveBuffer int // Index of the currently active buffer
+	Buffers      []*Buffer
+	ActiveBuffer int // Index of the currently active buffer (legacy single-pane selection, kept for callers that predate splits)
+
+	Root       Node // nil until the first split; see EnsureRoot
+	Focused    int  // ID of the pane most recently focused
+	nextPaneID int
 }
 
 // AddBuffer adds a new buffer/tab
-func (bm *BuffersManager) AddBuffer(buf Buffer) {
+func (bm *BuffersManager) AddBuffer(buf *Buffer) {
 	bm.Buffers = append(bm.Buffers, buf)
 }
 
@@ -39,3 +259,444 @@ func (bm *BuffersManager) SwitchBuffer(index int) {
 		bm.ActiveBuffer = index
 	}
 }
+
+// Node is either a *Pane (a leaf showing buffers) or a *SplitNode (an
+// internal node dividing its rect between two child Nodes).
+type Node interface {
+	isNode()
+}
+
+// Pane is a leaf in the split tree: an ordered list of open buffer
+// indices (into BuffersManager.Buffers) with one of them active, plus its
+// own cursor/scroll position so switching focus between panes doesn't
+// disturb where each one was left.
+type Pane struct {
+	ID      int
+	Buffers []int // indices into BuffersManager.Buffers
+	Active  int   // index into Buffers, not into BuffersManager.Buffers directly
+
+	CursorLine   int
+	CursorColumn int
+	ScrollOffset int
+}
+
+func (*Pane) isNode() {}
+
+// ActiveBufferIndex returns the BuffersManager.Buffers index the pane is
+// currently showing, or -1 if the pane holds no buffers.
+func (p *Pane) ActiveBufferIndex() int {
+	if p.Active < 0 || p.Active >= len(p.Buffers) {
+		return -1
+	}
+	return p.Buffers[p.Active]
+}
+
+// SplitNode divides its rect between two child Nodes along Ratio (the
+// fraction of the space A receives): stacked top/bottom when Vertical is
+// false (an Emacs-style "horizontal" split, the kind SplitHorizontal
+// makes), or side by side when Vertical is true (an Emacs-style
+// "vertical" split, the kind SplitVertical makes).
+type SplitNode struct {
+	Vertical bool
+	Ratio    float64
+	A, B     Node
+}
+
+func (*SplitNode) isNode() {}
+
+// EnsureRoot returns the tree's first Pane, creating a fresh one-pane
+// layout if nothing has been split yet.
+func (bm *BuffersManager) EnsureRoot() *Pane {
+	if bm.Root == nil {
+		bm.Root = &Pane{ID: bm.nextPaneID}
+		bm.nextPaneID++
+		bm.Focused = bm.Root.(*Pane).ID
+	}
+	if p, ok := bm.Root.(*Pane); ok {
+		return p
+	}
+	return firstPane(bm.Root)
+}
+
+// firstPane returns the leftmost/topmost leaf under n.
+func firstPane(n Node) *Pane {
+	switch t := n.(type) {
+	case *Pane:
+		return t
+	case *SplitNode:
+		return firstPane(t.A)
+	}
+	return nil
+}
+
+// findPane returns the Pane with the given ID under n, or nil.
+func findPane(n Node, id int) *Pane {
+	switch t := n.(type) {
+	case *Pane:
+		if t.ID == id {
+			return t
+		}
+	case *SplitNode:
+		if p := findPane(t.A, id); p != nil {
+			return p
+		}
+		return findPane(t.B, id)
+	}
+	return nil
+}
+
+// replacePane walks n looking for the Pane with id and swaps it for
+// replacement, returning the (possibly new) root and whether it found it.
+func replacePane(n Node, id int, replacement Node) (Node, bool) {
+	switch t := n.(type) {
+	case *Pane:
+		if t.ID == id {
+			return replacement, true
+		}
+		return n, false
+	case *SplitNode:
+		if newA, ok := replacePane(t.A, id, replacement); ok {
+			t.A = newA
+			return t, true
+		}
+		if newB, ok := replacePane(t.B, id, replacement); ok {
+			t.B = newB
+			return t, true
+		}
+	}
+	return n, false
+}
+
+// collapseParent finds the SplitNode whose A or B is the Pane paneID and
+// returns its other child - the sibling that inherits the freed space
+// when paneID is closed.
+func collapseParent(n Node, paneID int) (Node, bool) {
+	split, ok := n.(*SplitNode)
+	if !ok {
+		return nil, false
+	}
+	if p, ok := split.A.(*Pane); ok && p.ID == paneID {
+		return split.B, true
+	}
+	if p, ok := split.B.(*Pane); ok && p.ID == paneID {
+		return split.A, true
+	}
+	if replaced, ok := collapseParent(split.A, paneID); ok {
+		split.A = replaced
+		return split, true
+	}
+	if replaced, ok := collapseParent(split.B, paneID); ok {
+		split.B = replaced
+		return split, true
+	}
+	return nil, false
+}
+
+// Pane returns the Pane with the given ID, or nil if it doesn't exist.
+func (bm *BuffersManager) Pane(id int) *Pane {
+	return findPane(bm.Root, id)
+}
+
+// Leaves returns every open Pane in the split tree, left-to-right/top-to-
+// bottom, or nil if nothing has been split yet.
+func (bm *BuffersManager) Leaves() []*Pane {
+	if bm.Root == nil {
+		return nil
+	}
+	return leaves(bm.Root)
+}
+
+func leaves(n Node) []*Pane {
+	switch t := n.(type) {
+	case *Pane:
+		return []*Pane{t}
+	case *SplitNode:
+		return append(leaves(t.A), leaves(t.B)...)
+	}
+	return nil
+}
+
+// SplitHorizontal splits paneID into two stacked panes (a horizontal
+// divider: the new pane appears below), returning the new pane's ID, or
+// -1 if paneID doesn't exist.
+func (bm *BuffersManager) SplitHorizontal(paneID int) int {
+	return bm.split(paneID, false)
+}
+
+// SplitVertical splits paneID into two side-by-side panes (a vertical
+// divider: the new pane appears to the right), returning the new pane's
+// ID, or -1 if paneID doesn't exist.
+func (bm *BuffersManager) SplitVertical(paneID int) int {
+	return bm.split(paneID, true)
+}
+
+func (bm *BuffersManager) split(paneID int, vertical bool) int {
+	bm.EnsureRoot()
+	target := findPane(bm.Root, paneID)
+	if target == nil {
+		return -1
+	}
+
+	newPane := &Pane{ID: bm.nextPaneID}
+	bm.nextPaneID++
+
+	node := Node(&SplitNode{Vertical: vertical, Ratio: 0.5, A: target, B: newPane})
+	if newRoot, ok := replacePane(bm.Root, paneID, node); ok {
+		bm.Root = newRoot
+	}
+	bm.Focused = newPane.ID
+	return newPane.ID
+}
+
+// ClosePane removes paneID from the tree, collapsing its parent split so
+// the sibling takes over the freed space. Closing the last remaining pane
+// clears Root back to nil. Returns false if paneID doesn't exist; the
+// buffers it was showing stay open in BuffersManager.Buffers either way.
+func (bm *BuffersManager) ClosePane(paneID int) bool {
+	if bm.Root == nil {
+		return false
+	}
+	if p, ok := bm.Root.(*Pane); ok && p.ID == paneID {
+		bm.Root = nil
+		bm.Focused = 0
+		return true
+	}
+
+	sibling, ok := collapseParent(bm.Root, paneID)
+	if !ok {
+		return false
+	}
+	bm.Root = sibling
+	if leaf := firstPane(bm.Root); leaf != nil {
+		bm.Focused = leaf.ID
+	}
+	return true
+}
+
+// FocusPane sets Focused to paneID, returning false if it doesn't exist.
+func (bm *BuffersManager) FocusPane(paneID int) bool {
+	if findPane(bm.Root, paneID) == nil {
+		return false
+	}
+	bm.Focused = paneID
+	return true
+}
+
+// MoveBufferToPane moves the buffer at bufIdx (an index into Buffers) out
+// of whichever pane currently shows it and into paneID, making it that
+// pane's active buffer. Returns false if bufIdx or paneID is invalid.
+func (bm *BuffersManager) MoveBufferToPane(bufIdx, paneID int) bool {
+	if bufIdx < 0 || bufIdx >= len(bm.Buffers) {
+		return false
+	}
+	dest := findPane(bm.Root, paneID)
+	if dest == nil {
+		return false
+	}
+
+	for _, leaf := range bm.Leaves() {
+		for i, b := range leaf.Buffers {
+			if b == bufIdx {
+				leaf.Buffers = append(leaf.Buffers[:i], leaf.Buffers[i+1:]...)
+				if leaf.Active >= len(leaf.Buffers) {
+					leaf.Active = len(leaf.Buffers) - 1
+				}
+				break
+			}
+		}
+	}
+
+	dest.Buffers = append(dest.Buffers, bufIdx)
+	dest.Active = len(dest.Buffers) - 1
+	return true
+}
+
+// Rebalance resets every SplitNode's Ratio to an even 0.5, undoing any
+// manual resizing.
+func (bm *BuffersManager) Rebalance() {
+	rebalance(bm.Root)
+}
+
+func rebalance(n Node) {
+	split, ok := n.(*SplitNode)
+	if !ok {
+		return
+	}
+	split.Ratio = 0.5
+	rebalance(split.A)
+	rebalance(split.B)
+}
+
+// PaneRect is one leaf Pane's computed rectangle within the area passed
+// to Layout.
+type PaneRect struct {
+	PaneID              int
+	X, Y, Width, Height int
+}
+
+// Layout recursively divides a width x height rect amongst the tree's
+// leaves according to each SplitNode's orientation and Ratio. Returns nil
+// if nothing has been split yet.
+func (bm *BuffersManager) Layout(width, height int) []PaneRect {
+	if bm.Root == nil {
+		return nil
+	}
+	var rects []PaneRect
+	layoutNode(bm.Root, 0, 0, width, height, &rects)
+	return rects
+}
+
+func layoutNode(n Node, x, y, width, height int, rects *[]PaneRect) {
+	switch t := n.(type) {
+	case *Pane:
+		*rects = append(*rects, PaneRect{PaneID: t.ID, X: x, Y: y, Width: width, Height: height})
+	case *SplitNode:
+		if t.Vertical {
+			aw := int(float64(width) * t.Ratio)
+			layoutNode(t.A, x, y, aw, height, rects)
+			layoutNode(t.B, x+aw, y, width-aw, height, rects)
+		} else {
+			ah := int(float64(height) * t.Ratio)
+			layoutNode(t.A, x, y, width, ah, rects)
+			layoutNode(t.B, x, y+ah, width, height-ah, rects)
+		}
+	}
+}
+
+// layoutDTO is the JSON-friendly shape of one split-tree node: exactly
+// one of Pane/Split is set.
+type layoutDTO struct {
+	Pane  *paneDTO  `json:"pane,omitempty"`
+	Split *splitDTO `json:"split,omitempty"`
+}
+
+type paneDTO struct {
+	ID           int   `json:"id"`
+	Buffers      []int `json:"buffers"`
+	Active       int   `json:"active"`
+	CursorLine   int   `json:"cursorLine"`
+	CursorColumn int   `json:"cursorColumn"`
+	ScrollOffset int   `json:"scrollOffset"`
+}
+
+type splitDTO struct {
+	Vertical bool      `json:"vertical"`
+	Ratio    float64   `json:"ratio"`
+	A        layoutDTO `json:"a"`
+	B        layoutDTO `json:"b"`
+}
+
+// BufferInfo is the persisted shape of one open buffer: just enough to
+// know how to reopen it (Name is the path it was opened from) and how it
+// was normalized, not its content - that's re-read from disk on restore.
+type BufferInfo struct {
+	Name       string            `json:"name"`
+	LineEnding buffer.LineEnding `json:"lineEnding"`
+}
+
+// sessionDTO is the full persisted session: the split layout, which pane
+// is focused, and the buffer list the layout's indices refer to.
+type sessionDTO struct {
+	NextPaneID int         `json:"nextPaneId"`
+	Focused    int         `json:"focused"`
+	Layout     *layoutDTO  `json:"layout,omitempty"`
+	Buffers    []BufferInfo `json:"buffers"`
+}
+
+func toLayoutDTO(n Node) *layoutDTO {
+	if n == nil {
+		return nil
+	}
+	switch t := n.(type) {
+	case *Pane:
+		return &layoutDTO{Pane: &paneDTO{
+			ID: t.ID, Buffers: append([]int{}, t.Buffers...), Active: t.Active,
+			CursorLine: t.CursorLine, CursorColumn: t.CursorColumn, ScrollOffset: t.ScrollOffset,
+		}}
+	case *SplitNode:
+		return &layoutDTO{Split: &splitDTO{Vertical: t.Vertical, Ratio: t.Ratio, A: *toLayoutDTO(t.A), B: *toLayoutDTO(t.B)}}
+	}
+	return nil
+}
+
+func fromLayoutDTO(d *layoutDTO) Node {
+	if d == nil {
+		return nil
+	}
+	if d.Pane != nil {
+		return &Pane{
+			ID: d.Pane.ID, Buffers: append([]int{}, d.Pane.Buffers...), Active: d.Pane.Active,
+			CursorLine: d.Pane.CursorLine, CursorColumn: d.Pane.CursorColumn, ScrollOffset: d.Pane.ScrollOffset,
+		}
+	}
+	if d.Split != nil {
+		return &SplitNode{Vertical: d.Split.Vertical, Ratio: d.Split.Ratio, A: fromLayoutDTO(&d.Split.A), B: fromLayoutDTO(&d.Split.B)}
+	}
+	return nil
+}
+
+// DefaultLayoutPath returns the per-user session layout file location,
+// alongside the theme, keybindings, and history files under
+// ~/.config/letsgo-editor.
+func DefaultLayoutPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error resolving home directory: %v", err)
+	}
+	return filepath.Join(home, ".config", "letsgo-editor", "layout.json"), nil
+}
+
+// SaveLayout writes the split tree and buffer list to filename as JSON,
+// creating its parent directory if needed, so the next session can
+// restore its pane layout with LoadLayout.
+func (bm *BuffersManager) SaveLayout(filename string) error {
+	dto := sessionDTO{
+		NextPaneID: bm.nextPaneID,
+		Focused:    bm.Focused,
+		Layout:     toLayoutDTO(bm.Root),
+	}
+	for _, b := range bm.Buffers {
+		dto.Buffers = append(dto.Buffers, BufferInfo{Name: b.Name, LineEnding: b.LineEnding})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("error creating layout directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(dto, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling layout: %v", err)
+	}
+
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("error writing layout file: %v", err)
+	}
+	return nil
+}
+
+// LoadLayout replaces the split tree with the contents of filename. A
+// missing file is not an error; it just leaves the tree as it was. The
+// buffer list it restores only names which files were open and in what
+// order - reopening their content (e.g. via EditManager.OpenFile) is the
+// caller's job, the same way LoadLayout itself never touches disk beyond
+// filename.
+func (bm *BuffersManager) LoadLayout(filename string) ([]BufferInfo, error) {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading layout file: %v", err)
+	}
+
+	var dto sessionDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("error parsing layout file: %v", err)
+	}
+
+	bm.nextPaneID = dto.NextPaneID
+	bm.Focused = dto.Focused
+	bm.Root = fromLayoutDTO(dto.Layout)
+	return dto.Buffers, nil
+}
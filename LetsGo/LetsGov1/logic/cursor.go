@@ -0,0 +1,346 @@
+/* Cursor/selection/word-motion semantics for Buffer, following the shape
+of micro's actions.go and phi's Cursor: a (line, col) caret plus an
+optional selection anchor, with motions that walk the buffer's lines and
+classify runes to find word boundaries. */
+
+package logic
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Loc is a (line, col) position in a buffer. Both are 0-indexed; Col
+// counts runes, not bytes.
+type Loc struct {
+	Line int
+	Col  int
+}
+
+func locLess(a, b Loc) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Col < b.Col
+}
+
+// Cursor tracks the caret and an optional in-progress selection
+type Cursor struct {
+	Loc            Loc
+	SelectionStart Loc
+	SelectionEnd   Loc
+	selecting      bool
+}
+
+// HasSelection reports whether a non-empty selection is active
+func (c *Cursor) HasSelection() bool {
+	return c.selecting && c.SelectionStart != c.SelectionEnd
+}
+
+// ClearSelection drops any in-progress selection without moving Loc
+func (c *Cursor) ClearSelection() {
+	c.selecting = false
+	c.SelectionStart = Loc{}
+	c.SelectionEnd = Loc{}
+}
+
+// extendSelection anchors a selection at the cursor's pre-move location
+// (if one isn't already open) and extends it to to.
+func (c *Cursor) extendSelection(to Loc) {
+	c.extendSelectionFrom(c.Loc, to)
+}
+
+// extendSelectionFrom anchors a selection at from (if one isn't already
+// open) and extends it to to. Used instead of extendSelection by callers
+// that must move Cursor.Loc before anchoring, so the pre-move location has
+// to be captured and passed in explicitly rather than read off c.Loc.
+func (c *Cursor) extendSelectionFrom(from, to Loc) {
+	if !c.selecting {
+		c.SelectionStart = from
+		c.selecting = true
+	}
+	c.Loc = to
+	c.SelectionEnd = to
+}
+
+// orderedSelection returns the selection endpoints in document order
+func (c *Cursor) orderedSelection() (Loc, Loc) {
+	if locLess(c.SelectionEnd, c.SelectionStart) {
+		return c.SelectionEnd, c.SelectionStart
+	}
+	return c.SelectionStart, c.SelectionEnd
+}
+
+// lineRunes returns line n's content as runes, or nil if n is out of range
+func (b *Buffer) lineRunes(n int) []rune {
+	return []rune(b.LineAt(n))
+}
+
+// clampCol pins Cursor.Loc.Col to the current line's length
+func (b *Buffer) clampCol() {
+	n := len(b.lineRunes(b.Cursor.Loc.Line))
+	if b.Cursor.Loc.Col > n {
+		b.Cursor.Loc.Col = n
+	}
+}
+
+// clampLine pins Cursor.Loc.Line to the buffer's last line, then clamps Col
+func (b *Buffer) clampLine() {
+	if last := b.LineCount() - 1; b.Cursor.Loc.Line > last {
+		b.Cursor.Loc.Line = last
+	}
+	b.clampCol()
+}
+
+// offsetForLoc converts a (line, col) position to a rune offset into the
+// whole buffer
+func (b *Buffer) offsetForLoc(loc Loc) int {
+	lines := strings.Split(b.String(), "\n")
+	offset := 0
+	for i := 0; i < loc.Line && i < len(lines); i++ {
+		offset += utf8.RuneCountInString(lines[i]) + 1
+	}
+	if loc.Line < len(lines) {
+		col := loc.Col
+		if lineLen := utf8.RuneCountInString(lines[loc.Line]); col > lineLen {
+			col = lineLen
+		}
+		offset += col
+	}
+	return offset
+}
+
+// locForOffset converts a rune offset into the whole buffer to a
+// (line, col) position
+func (b *Buffer) locForOffset(offset int) Loc {
+	lines := strings.Split(b.String(), "\n")
+	remaining := offset
+	for i, line := range lines {
+		n := utf8.RuneCountInString(line)
+		if remaining <= n {
+			return Loc{Line: i, Col: remaining}
+		}
+		remaining -= n + 1
+	}
+	if len(lines) == 0 {
+		return Loc{}
+	}
+	last := len(lines) - 1
+	return Loc{Line: last, Col: utf8.RuneCountInString(lines[last])}
+}
+
+// wordRight returns the position reached by skipping whitespace then
+// the following run of identifier runes, starting from loc
+func (b *Buffer) wordRight(loc Loc) Loc {
+	runes := []rune(b.String())
+	n := len(runes)
+	i := b.offsetForLoc(loc)
+	for i < n && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	for i < n && isWordRune(runes[i]) {
+		i++
+	}
+	return b.locForOffset(i)
+}
+
+// wordLeft returns the position reached by skipping whitespace then the
+// preceding run of identifier runes, walking backward from loc
+func (b *Buffer) wordLeft(loc Loc) Loc {
+	runes := []rune(b.String())
+	i := b.offsetForLoc(loc)
+	for i > 0 && unicode.IsSpace(runes[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(runes[i-1]) {
+		i--
+	}
+	return b.locForOffset(i)
+}
+
+func (b *Buffer) moveForwardChar() {
+	if b.Cursor.Loc.Col < len(b.lineRunes(b.Cursor.Loc.Line)) {
+		b.Cursor.Loc.Col++
+		return
+	}
+	if b.Cursor.Loc.Line < b.LineCount()-1 {
+		b.Cursor.Loc.Line++
+		b.Cursor.Loc.Col = 0
+	}
+}
+
+func (b *Buffer) moveBackwardChar() {
+	if b.Cursor.Loc.Col > 0 {
+		b.Cursor.Loc.Col--
+		return
+	}
+	if b.Cursor.Loc.Line > 0 {
+		b.Cursor.Loc.Line--
+		b.Cursor.Loc.Col = len(b.lineRunes(b.Cursor.Loc.Line))
+	}
+}
+
+// GoForwardChar moves the cursor one rune forward. With an active
+// selection it collapses to the selection's right edge instead, same as
+// most editors.
+func (b *Buffer) GoForwardChar() {
+	if b.Cursor.HasSelection() {
+		_, end := b.Cursor.orderedSelection()
+		b.Cursor.ClearSelection()
+		b.Cursor.Loc = end
+		return
+	}
+	b.Cursor.ClearSelection()
+	b.moveForwardChar()
+}
+
+// GoBackwardChar moves the cursor one rune backward, collapsing to the
+// selection's left edge if one is active.
+func (b *Buffer) GoBackwardChar() {
+	if b.Cursor.HasSelection() {
+		start, _ := b.Cursor.orderedSelection()
+		b.Cursor.ClearSelection()
+		b.Cursor.Loc = start
+		return
+	}
+	b.Cursor.ClearSelection()
+	b.moveBackwardChar()
+}
+
+func (b *Buffer) GoToNextLine() {
+	b.Cursor.ClearSelection()
+	if b.Cursor.Loc.Line < b.LineCount()-1 {
+		b.Cursor.Loc.Line++
+		b.clampCol()
+	}
+}
+
+func (b *Buffer) GoToPreviousLine() {
+	b.Cursor.ClearSelection()
+	if b.Cursor.Loc.Line > 0 {
+		b.Cursor.Loc.Line--
+		b.clampCol()
+	}
+}
+
+func (b *Buffer) GoToStartOfLine() {
+	b.Cursor.ClearSelection()
+	b.Cursor.Loc.Col = 0
+}
+
+func (b *Buffer) GoToEndOfLine() {
+	b.Cursor.ClearSelection()
+	b.Cursor.Loc.Col = len(b.lineRunes(b.Cursor.Loc.Line))
+}
+
+func (b *Buffer) GoToStartOfFile() {
+	b.Cursor.ClearSelection()
+	b.Cursor.Loc = Loc{}
+}
+
+func (b *Buffer) GoToEndOfFile() {
+	b.Cursor.ClearSelection()
+	last := b.LineCount() - 1
+	b.Cursor.Loc = Loc{Line: last, Col: len(b.lineRunes(last))}
+}
+
+func (b *Buffer) GoToNextWord() {
+	b.Cursor.ClearSelection()
+	b.Cursor.Loc = b.wordRight(b.Cursor.Loc)
+}
+
+func (b *Buffer) GoToPreviousWord() {
+	b.Cursor.ClearSelection()
+	b.Cursor.Loc = b.wordLeft(b.Cursor.Loc)
+}
+
+// SelectAll selects the entire buffer
+func (b *Buffer) SelectAll() {
+	last := b.LineCount() - 1
+	end := Loc{Line: last, Col: len(b.lineRunes(last))}
+	b.Cursor.SelectionStart = Loc{}
+	b.Cursor.SelectionEnd = end
+	b.Cursor.Loc = end
+	b.Cursor.selecting = true
+}
+
+// SelectLeft/Right/Up/Down/WordLeft/WordRight extend the selection from
+// the cursor's current location, so shift-held motions can reuse the
+// same navigation as their unshifted counterparts.
+func (b *Buffer) SelectLeft() {
+	from := b.Cursor.Loc
+	b.moveBackwardChar()
+	b.Cursor.extendSelectionFrom(from, b.Cursor.Loc)
+}
+
+func (b *Buffer) SelectRight() {
+	from := b.Cursor.Loc
+	b.moveForwardChar()
+	b.Cursor.extendSelectionFrom(from, b.Cursor.Loc)
+}
+
+func (b *Buffer) SelectUp() {
+	from := b.Cursor.Loc
+	to := b.Cursor.Loc
+	if to.Line > 0 {
+		to.Line--
+	}
+	b.Cursor.Loc = to
+	b.clampCol()
+	b.Cursor.extendSelectionFrom(from, b.Cursor.Loc)
+}
+
+func (b *Buffer) SelectDown() {
+	from := b.Cursor.Loc
+	to := b.Cursor.Loc
+	if to.Line < b.LineCount()-1 {
+		to.Line++
+	}
+	b.Cursor.Loc = to
+	b.clampCol()
+	b.Cursor.extendSelectionFrom(from, b.Cursor.Loc)
+}
+
+func (b *Buffer) SelectWordLeft() {
+	b.Cursor.extendSelection(b.wordLeft(b.Cursor.Loc))
+}
+
+func (b *Buffer) SelectWordRight() {
+	b.Cursor.extendSelection(b.wordRight(b.Cursor.Loc))
+}
+
+// DeleteWord deletes from the cursor to the start of the next word and
+// records the deletion as an undoable edit.
+func (b *Buffer) DeleteWord() {
+	start := b.offsetForLoc(b.Cursor.Loc)
+	end := b.offsetForLoc(b.wordRight(b.Cursor.Loc))
+	if end <= start {
+		return
+	}
+	b.Delete(start, end)
+	b.Cursor.ClearSelection()
+	b.Cursor.Loc = b.locForOffset(start)
+}
+
+// DeleteLine deletes the cursor's current line, including its trailing
+// newline when it isn't the last line, and records the deletion as an
+// undoable edit.
+func (b *Buffer) DeleteLine() {
+	lines := strings.Split(b.String(), "\n")
+	n := b.Cursor.Loc.Line
+	if n < 0 || n >= len(lines) {
+		return
+	}
+
+	start := b.offsetForLoc(Loc{Line: n, Col: 0})
+	end := start + utf8.RuneCountInString(lines[n])
+	if n < len(lines)-1 {
+		end++ // swallow the trailing newline too
+	}
+
+	b.Delete(start, end)
+	b.Cursor.ClearSelection()
+	b.Cursor.Loc = Loc{Line: n, Col: 0}
+	b.clampLine()
+}
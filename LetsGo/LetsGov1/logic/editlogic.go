@@ -37,21 +37,29 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+
+	"letsgo-editor/buffer"
+	"letsgo-editor/syntax"
 )
 
 // EditManager handles all file and text manipulation actions
 //
 type EditManager struct {
-	UndoStack []string // Stack for undo operations
-	RedoStack []string // Stack for redo operations
-	CurrentFile string // Currently open file path
+	Doc         *Buffer // active document; rope-backed with its own undo/redo history
+	CurrentFile string  // currently open file path
+
+	LineEnding       buffer.LineEnding // original terminator style of CurrentFile
+	DetectedEncoding string            // best-guess text encoding, e.g. "utf-8"
+
+	SyntaxRegistry *syntax.Registry // languages available for highlighting, keyed by extension
 }
 
 // File operations
 func (em *EditManager) NewFile() {
 	em.CurrentFile = ""
-	em.UndoStack = []string{}
-	em.RedoStack = []string{}
+	em.LineEnding = buffer.LF
+	em.DetectedEncoding = "utf-8"
+	em.Doc = NewBuffer("untitled", "", em.LineEnding)
 	fmt.Println("New file created")
 }
 
@@ -60,17 +68,28 @@ func (em *EditManager) OpenFile(filename string) {
 		fmt.Println("No filename provided")
 		return
 	}
-	
+
 	content, err := ioutil.ReadFile(filename)
 	if err != nil {
 		fmt.Printf("Error opening file %s: %v\n", filename, err)
 		return
 	}
-	
+
+	normalized, detected := buffer.Normalize(string(content))
+
 	em.CurrentFile = filename
-	em.UndoStack = []string{string(content)}
-	em.RedoStack = []string{}
-	fmt.Printf("File opened: %s\n", filename)
+	em.LineEnding = detected
+	em.DetectedEncoding = "utf-8"
+	em.Doc = NewBuffer(filename, normalized, detected)
+	em.Doc.SetLanguage(em.SyntaxRegistry.ForFile(filename))
+	fmt.Printf("File opened: %s (line endings: %s)\n", filename, em.LineEnding)
+}
+
+// SetLineEnding overrides the detected line ending, e.g. from the
+// set_line_ending_lf/set_line_ending_crlf keybind actions.
+func (em *EditManager) SetLineEnding(le buffer.LineEnding) {
+	em.LineEnding = le
+	fmt.Printf("Line ending set to: %s\n", le)
 }
 
 func (em *EditManager) SaveFile() {
@@ -78,14 +97,14 @@ func (em *EditManager) SaveFile() {
 		fmt.Println("No file to save. Use SaveAs instead.")
 		return
 	}
-	
-	if len(em.UndoStack) == 0 {
+
+	if em.Doc == nil {
 		fmt.Println("No content to save")
 		return
 	}
-	
-	content := em.UndoStack[len(em.UndoStack)-1]
-	err := ioutil.WriteFile(em.CurrentFile, []byte(content), 0644)
+
+	onDisk := buffer.Emit(em.Doc.String(), em.LineEnding)
+	err := ioutil.WriteFile(em.CurrentFile, []byte(onDisk), 0644)
 	if err != nil {
 		fmt.Printf("Error saving file: %v\n", err)
 		return
@@ -124,8 +143,7 @@ func (em *EditManager) SaveAll() {
 
 func (em *EditManager) CloseFile() {
 	em.CurrentFile = ""
-	em.UndoStack = []string{}
-	em.RedoStack = []string{}
+	em.Doc = nil
 	fmt.Println("File closed")
 }
 
@@ -155,83 +173,160 @@ func (em *EditManager) DeleteFile() {
 
 // Text manipulation
 func (em *EditManager) Undo() {
-	if len(em.UndoStack) <= 1 {
+	if em.Doc == nil || !em.Doc.Undo() {
 		fmt.Println("Nothing to undo")
 		return
 	}
-	
-	// Move current state to redo stack
-	current := em.UndoStack[len(em.UndoStack)-1]
-	em.RedoStack = append(em.RedoStack, current)
-	
-	// Remove current state from undo stack
-	em.UndoStack = em.UndoStack[:len(em.UndoStack)-1]
-	
+
 	fmt.Println("Undo performed")
 }
 
 func (em *EditManager) Redo() {
-	if len(em.RedoStack) == 0 {
+	if em.Doc == nil || !em.Doc.Redo() {
 		fmt.Println("Nothing to redo")
 		return
 	}
-	
-	// Move state from redo to undo stack
-	state := em.RedoStack[len(em.RedoStack)-1]
-	em.RedoStack = em.RedoStack[:len(em.RedoStack)-1]
-	em.UndoStack = append(em.UndoStack, state)
-	
+
 	fmt.Println("Redo performed")
 }
 
+// The motions below delegate to the active document's Cursor/selection
+// logic (see cursor.go); they're no-ops when no document is open.
+
 func (em *EditManager) SelectAll() {
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.SelectAll()
 	fmt.Println("All text selected")
 }
 
 func (em *EditManager) GoToStartOfLine() {
-	fmt.Println("Cursor moved to start of line")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoToStartOfLine()
 }
 
 func (em *EditManager) GoToEndOfLine() {
-	fmt.Println("Cursor moved to end of line")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoToEndOfLine()
 }
 
 func (em *EditManager) GoToStartOfFile() {
-	fmt.Println("Cursor moved to start of file")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoToStartOfFile()
 }
 
 func (em *EditManager) GoToEndOfFile() {
-	fmt.Println("Cursor moved to end of file")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoToEndOfFile()
 }
 
 func (em *EditManager) GoToNextWord() {
-	fmt.Println("Cursor moved to next word")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoToNextWord()
 }
 
 func (em *EditManager) GoToPreviousWord() {
-	fmt.Println("Cursor moved to previous word")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoToPreviousWord()
 }
 
 func (em *EditManager) DeleteWord() {
-	fmt.Println("Word deleted")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.DeleteWord()
 }
 
 func (em *EditManager) DeleteLine() {
-	fmt.Println("Line deleted")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.DeleteLine()
 }
 
 func (em *EditManager) GoForwardChar() {
-	fmt.Println("Cursor moved forward one character")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoForwardChar()
 }
 
 func (em *EditManager) GoBackwardChar() {
-	fmt.Println("Cursor moved backward one character")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoBackwardChar()
 }
 
 func (em *EditManager) GoToNextLine() {
-	fmt.Println("Cursor moved to next line")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoToNextLine()
 }
 
 func (em *EditManager) GoToPreviousLine() {
-	fmt.Println("Cursor moved to previous line")
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.GoToPreviousLine()
+}
+
+// SelectLeft/Right/Up/Down/WordLeft/WordRight extend the active
+// document's selection instead of moving the plain cursor, for
+// shift-held keybindings.
+
+func (em *EditManager) SelectLeft() {
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.SelectLeft()
+}
+
+func (em *EditManager) SelectRight() {
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.SelectRight()
+}
+
+func (em *EditManager) SelectUp() {
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.SelectUp()
+}
+
+func (em *EditManager) SelectDown() {
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.SelectDown()
+}
+
+func (em *EditManager) SelectWordLeft() {
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.SelectWordLeft()
+}
+
+func (em *EditManager) SelectWordRight() {
+	if em.Doc == nil {
+		return
+	}
+	em.Doc.SelectWordRight()
 }
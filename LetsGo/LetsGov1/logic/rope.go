@@ -0,0 +1,96 @@
+/* A small rope implementation backing logic.Buffer, inspired by the phi
+editor's use of go-rope: a binary tree of string leaves so Insert/Delete/
+Slice only touch the path from the root to the edited offset instead of
+copying the whole document on every keystroke. */
+
+package logic
+
+import "unicode/utf8"
+
+// rope is a binary tree of string leaves. Interior nodes carry weight,
+// the rune length of their left subtree, so a rune offset can be routed
+// to the correct leaf without measuring every leaf along the way.
+type rope struct {
+	left, right *rope
+	weight      int
+	leaf        string
+}
+
+// newRope builds a rope holding s as a single leaf
+func newRope(s string) *rope {
+	return &rope{leaf: s, weight: utf8.RuneCountInString(s)}
+}
+
+func (r *rope) isLeaf() bool {
+	return r.left == nil && r.right == nil
+}
+
+// Len returns the rune length of the rope
+func (r *rope) Len() int {
+	if r == nil {
+		return 0
+	}
+	if r.isLeaf() {
+		return r.weight
+	}
+	return r.weight + r.right.Len()
+}
+
+// concat joins two ropes into one, skipping empty sides
+func concat(a, b *rope) *rope {
+	if a.Len() == 0 {
+		return b
+	}
+	if b.Len() == 0 {
+		return a
+	}
+	return &rope{left: a, right: b, weight: a.Len()}
+}
+
+// split divides r into the runes before idx and the runes at/after idx
+func (r *rope) split(idx int) (*rope, *rope) {
+	if r == nil || r.Len() == 0 {
+		return newRope(""), newRope("")
+	}
+	if r.isLeaf() {
+		runes := []rune(r.leaf)
+		return newRope(string(runes[:idx])), newRope(string(runes[idx:]))
+	}
+	if idx < r.weight {
+		l, rr := r.left.split(idx)
+		return l, concat(rr, r.right)
+	}
+	l, rr := r.right.split(idx - r.weight)
+	return concat(r.left, l), rr
+}
+
+// Insert returns a new rope with text inserted at rune offset pos
+func (r *rope) Insert(pos int, text string) *rope {
+	l, rr := r.split(pos)
+	return concat(concat(l, newRope(text)), rr)
+}
+
+// Delete returns a new rope with the runes in [start,end) removed
+func (r *rope) Delete(start, end int) *rope {
+	l, _ := r.split(start)
+	_, rr := r.split(end)
+	return concat(l, rr)
+}
+
+// Slice returns the runes in [start,end) as a string
+func (r *rope) Slice(start, end int) string {
+	_, rest := r.split(start)
+	part, _ := rest.split(end - start)
+	return part.String()
+}
+
+// String flattens the rope to a single string
+func (r *rope) String() string {
+	if r == nil {
+		return ""
+	}
+	if r.isLeaf() {
+		return r.leaf
+	}
+	return r.left.String() + r.right.String()
+}
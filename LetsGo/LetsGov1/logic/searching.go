@@ -19,50 +19,72 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// Algorithm selects how SearchManager looks for matches
+type Algorithm int
+
+const (
+	AlgoLinear Algorithm = iota
+	AlgoRegex
+	AlgoAhoCorasick
+)
+
+// Match describes a single found occurrence.
+// PatternID is -1 for single-term searches (linear/regex) and the index
+// into the terms slice passed to SearchMulti for Aho-Corasick matches.
+type Match struct {
+	Start     int
+	End       int
+	PatternID int
+}
+
 // SearchManager manages searching and replacing in the editor
 //
 type SearchManager struct {
 	CaseSensitive bool
 	UseRegex      bool
-	Results       []int // Indices of found matches
-	CurrentIndex  int   // Current match index
-	LastTerm      string // Last searched term
+	WholeWord     bool
+	Results       []Match // Found matches, in text order
+	CurrentIndex  int     // Current match index
+	LastTerm      string  // Last searched term
 }
 
 // Search searches for a term in the given text
 func (sm *SearchManager) Search(text, term string) {
 	if term == "" {
-		sm.Results = []int{}
+		sm.Results = []Match{}
 		sm.CurrentIndex = 0
 		return
 	}
-	
+
 	sm.LastTerm = term
-	sm.Results = []int{}
+	sm.Results = []Match{}
 	sm.CurrentIndex = 0
-	
+
 	searchText := text
 	searchTerm := term
-	
+
 	// Handle case sensitivity
 	if !sm.CaseSensitive {
 		searchText = strings.ToLower(text)
 		searchTerm = strings.ToLower(term)
 	}
-	
+
 	if sm.UseRegex {
-		// Use regex search
-		pattern, err := regexp.Compile(searchTerm)
+		// Use regex search. WholeWord wraps the user's pattern in \b(?:...)\b
+		// so it only matches at word boundaries, same as the plain path below.
+		pattern, err := regexp.Compile(sm.wrapWholeWord(searchTerm))
 		if err != nil {
 			fmt.Printf("Invalid regex pattern: %v\n", err)
 			return
 		}
-		
+
 		matches := pattern.FindAllStringIndex(searchText, -1)
 		for _, match := range matches {
-			sm.Results = append(sm.Results, match[0])
+			sm.Results = append(sm.Results, Match{Start: match[0], End: match[1], PatternID: -1})
 		}
 	} else {
 		// Use linear search (faster for simple text)
@@ -73,34 +95,222 @@ func (sm *SearchManager) Search(text, term string) {
 				break
 			}
 			actualPos := index + pos
-			sm.Results = append(sm.Results, actualPos)
+			if !sm.WholeWord || isWholeWordMatch(searchText, actualPos, len(searchTerm)) {
+				sm.Results = append(sm.Results, Match{Start: actualPos, End: actualPos + len(searchTerm), PatternID: -1})
+			}
 			index = actualPos + 1
 		}
 	}
-	
+
 	fmt.Printf("Found %d matches for '%s'\n", len(sm.Results), term)
 	if len(sm.Results) > 0 {
 		fmt.Printf("Currently at match 1 of %d\n", len(sm.Results))
 	}
 }
 
+// wrapWholeWord wraps pattern in \b(?:...)\b when WholeWord is set, so the
+// regex path only matches at word boundaries.
+func (sm *SearchManager) wrapWholeWord(pattern string) string {
+	if !sm.WholeWord {
+		return pattern
+	}
+	return `\b(?:` + pattern + `)\b`
+}
+
+// isWholeWordMatch reports whether the match occupying the byte range
+// [pos, pos+length) in text is bounded by non-word runes (or text
+// start/end) on both sides, so e.g. WholeWord rejects "cat" inside
+// "category". Runes are decoded explicitly rather than indexed as bytes so
+// multi-byte characters on either side of the match are classified
+// correctly.
+func isWholeWordMatch(text string, pos, length int) bool {
+	if pos > 0 {
+		r, _ := utf8.DecodeLastRuneInString(text[:pos])
+		if isWordRune(r) {
+			return false
+		}
+	}
+
+	end := pos + length
+	if end < len(text) {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isWordRune reports whether r can be part of a "word" for whole-word
+// matching purposes.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// SearchMulti finds every occurrence of any of terms in text in a single pass
+// using an Aho-Corasick automaton, and stores them in sm.Results tagged with
+// the index of the term (PatternID) that produced each hit. This is the
+// AlgoAhoCorasick mode: O(n + sum(len(terms)) + matches) instead of the
+// O(n*m) cost of running the linear search once per term.
+func (sm *SearchManager) SearchMulti(text string, terms []string) {
+	sm.Results = []Match{}
+	sm.CurrentIndex = 0
+	sm.LastTerm = ""
+
+	if len(terms) == 0 {
+		return
+	}
+
+	searchText := text
+	searchTerms := terms
+	if !sm.CaseSensitive {
+		searchText = strings.ToLower(text)
+		searchTerms = make([]string, len(terms))
+		for i, t := range terms {
+			searchTerms[i] = strings.ToLower(t)
+		}
+	}
+
+	automaton := buildAhoCorasick(searchTerms)
+	sm.Results = automaton.scan(searchText)
+
+	fmt.Printf("Found %d matches for %d patterns\n", len(sm.Results), len(terms))
+	if len(sm.Results) > 0 {
+		fmt.Printf("Currently at match 1 of %d\n", len(sm.Results))
+	}
+}
+
+// acNode is a single trie node of the Aho-Corasick automaton
+type acNode struct {
+	children map[rune]int // rune -> node index
+	fail     int          // failure link (node index)
+	output   []int        // pattern IDs that end at this node (via output links)
+}
+
+// acAutomaton is a built Aho-Corasick trie with failure and output links
+type acAutomaton struct {
+	nodes   []acNode
+	terms   []string
+	lengths []int
+}
+
+// buildAhoCorasick constructs the trie, then adds failure links via BFS so a
+// mismatch jumps to the longest proper suffix of the current node that is
+// also a prefix somewhere in the trie.
+func buildAhoCorasick(terms []string) *acAutomaton {
+	a := &acAutomaton{
+		nodes:   []acNode{{children: map[rune]int{}, fail: 0}},
+		terms:   terms,
+		lengths: make([]int, len(terms)),
+	}
+
+	for id, term := range terms {
+		runes := []rune(term)
+		a.lengths[id] = len(runes)
+		cur := 0
+		for _, r := range runes {
+			next, ok := a.nodes[cur].children[r]
+			if !ok {
+				a.nodes = append(a.nodes, acNode{children: map[rune]int{}, fail: 0})
+				next = len(a.nodes) - 1
+				a.nodes[cur].children[r] = next
+			}
+			cur = next
+		}
+		a.nodes[cur].output = append(a.nodes[cur].output, id)
+	}
+
+	// BFS to build failure links and merge output sets along the way
+	queue := []int{}
+	for _, child := range a.nodes[0].children {
+		a.nodes[child].fail = 0
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for r, child := range a.nodes[cur].children {
+			queue = append(queue, child)
+
+			fail := a.nodes[cur].fail
+			for fail != 0 {
+				if _, ok := a.nodes[fail].children[r]; ok {
+					break
+				}
+				fail = a.nodes[fail].fail
+			}
+
+			target := 0
+			if next, ok := a.nodes[fail].children[r]; ok {
+				target = next
+			}
+
+			a.nodes[child].fail = target
+			a.nodes[child].output = append(a.nodes[child].output, a.nodes[target].output...)
+		}
+	}
+
+	return a
+}
+
+// scan walks text once, following goto edges and failure links, emitting a
+// Match for every position whose node has a non-empty output set. Match
+// offsets are byte offsets (matching Search/Replace) even though the
+// automaton itself is walked rune by rune, so byteOffsets[i] records where
+// rune i starts in text.
+func (a *acAutomaton) scan(text string) []Match {
+	var results []Match
+	cur := 0
+
+	runes := []rune(text)
+	byteOffsets := make([]int, len(runes)+1)
+	pos := 0
+	for i, r := range runes {
+		byteOffsets[i] = pos
+		pos += utf8.RuneLen(r)
+	}
+	byteOffsets[len(runes)] = pos
+
+	for i, r := range runes {
+		for cur != 0 {
+			if _, ok := a.nodes[cur].children[r]; ok {
+				break
+			}
+			cur = a.nodes[cur].fail
+		}
+		if next, ok := a.nodes[cur].children[r]; ok {
+			cur = next
+		}
+
+		for _, id := range a.nodes[cur].output {
+			start := i - a.lengths[id] + 1
+			results = append(results, Match{Start: byteOffsets[start], End: byteOffsets[i+1], PatternID: id})
+		}
+	}
+
+	return results
+}
+
 // Replace replaces the current or all found terms with newText
 func (sm *SearchManager) Replace(text, newText string, replaceAll bool) string {
 	if len(sm.Results) == 0 {
 		fmt.Println("No search results to replace")
 		return text
 	}
-	
+
 	if replaceAll {
 		// Replace all occurrences
 		result := text
 		searchTerm := sm.LastTerm
-		
+
 		if !sm.CaseSensitive {
 			// For case-insensitive replacement, we need to be more careful
 			// This is a simplified implementation
 			if sm.UseRegex {
-				pattern, err := regexp.Compile("(?i)" + searchTerm)
+				pattern, err := regexp.Compile("(?i)" + sm.wrapWholeWord(searchTerm))
 				if err == nil {
 					result = pattern.ReplaceAllString(result, newText)
 				}
@@ -108,18 +318,23 @@ func (sm *SearchManager) Replace(text, newText string, replaceAll bool) string {
 				// Simple case-insensitive replacement
 				lowerText := strings.ToLower(result)
 				lowerTerm := strings.ToLower(searchTerm)
-				
+
 				var newResult strings.Builder
 				lastIndex := 0
-				
+
 				for {
 					index := strings.Index(lowerText[lastIndex:], lowerTerm)
 					if index == -1 {
 						newResult.WriteString(result[lastIndex:])
 						break
 					}
-					
+
 					actualIndex := lastIndex + index
+					if sm.WholeWord && !isWholeWordMatch(lowerText, actualIndex, len(lowerTerm)) {
+						newResult.WriteString(result[lastIndex : actualIndex+len(searchTerm)])
+						lastIndex = actualIndex + len(searchTerm)
+						continue
+					}
 					newResult.WriteString(result[lastIndex:actualIndex])
 					newResult.WriteString(newText)
 					lastIndex = actualIndex + len(searchTerm)
@@ -129,47 +344,68 @@ func (sm *SearchManager) Replace(text, newText string, replaceAll bool) string {
 		} else {
 			// Case-sensitive replacement
 			if sm.UseRegex {
-				pattern, err := regexp.Compile(searchTerm)
+				pattern, err := regexp.Compile(sm.wrapWholeWord(searchTerm))
 				if err == nil {
 					result = pattern.ReplaceAllString(result, newText)
 				}
-			} else {
+			} else if !sm.WholeWord {
 				result = strings.ReplaceAll(result, searchTerm, newText)
+			} else {
+				var newResult strings.Builder
+				lastIndex := 0
+				for {
+					index := strings.Index(result[lastIndex:], searchTerm)
+					if index == -1 {
+						newResult.WriteString(result[lastIndex:])
+						break
+					}
+					actualIndex := lastIndex + index
+					newResult.WriteString(result[lastIndex:actualIndex])
+					if isWholeWordMatch(result, actualIndex, len(searchTerm)) {
+						newResult.WriteString(newText)
+					} else {
+						newResult.WriteString(searchTerm)
+					}
+					lastIndex = actualIndex + len(searchTerm)
+				}
+				result = newResult.String()
 			}
 		}
-		
+
 		fmt.Printf("Replaced all %d occurrences\n", len(sm.Results))
 		// Clear results after replace all
-		sm.Results = []int{}
+		sm.Results = []Match{}
 		sm.CurrentIndex = 0
 		return result
-	} else {
-		// Replace only current match
-		if sm.CurrentIndex >= 0 && sm.CurrentIndex < len(sm.Results) {
-			pos := sm.Results[sm.CurrentIndex]
-			termLen := len(sm.LastTerm)
-			
-			// Replace at the specific position
-			result := text[:pos] + newText + text[pos+termLen:]
-			
-			fmt.Printf("Replaced match %d of %d\n", sm.CurrentIndex+1, len(sm.Results))
-			
-			// Update remaining result positions
-			lenDiff := len(newText) - termLen
-			for i := sm.CurrentIndex + 1; i < len(sm.Results); i++ {
-				sm.Results[i] += lenDiff
-			}
-			
-			// Remove current match from results
-			sm.Results = append(sm.Results[:sm.CurrentIndex], sm.Results[sm.CurrentIndex+1:]...)
-			if sm.CurrentIndex >= len(sm.Results) && len(sm.Results) > 0 {
-				sm.CurrentIndex = len(sm.Results) - 1
-			}
-			
-			return result
+	}
+
+	// Replace only current match. Use the match's own Start/End rather than
+	// assuming every result is len(LastTerm) long, which used to corrupt
+	// regex-mode replacements whenever a pattern matched a variable-length
+	// substring.
+	if sm.CurrentIndex >= 0 && sm.CurrentIndex < len(sm.Results) {
+		match := sm.Results[sm.CurrentIndex]
+
+		result := text[:match.Start] + newText + text[match.End:]
+
+		fmt.Printf("Replaced match %d of %d\n", sm.CurrentIndex+1, len(sm.Results))
+
+		// Update remaining result positions
+		lenDiff := len(newText) - (match.End - match.Start)
+		for i := sm.CurrentIndex + 1; i < len(sm.Results); i++ {
+			sm.Results[i].Start += lenDiff
+			sm.Results[i].End += lenDiff
+		}
+
+		// Remove current match from results
+		sm.Results = append(sm.Results[:sm.CurrentIndex], sm.Results[sm.CurrentIndex+1:]...)
+		if sm.CurrentIndex >= len(sm.Results) && len(sm.Results) > 0 {
+			sm.CurrentIndex = len(sm.Results) - 1
 		}
+
+		return result
 	}
-	
+
 	return text
 }
 
@@ -195,16 +431,16 @@ func (sm *SearchManager) Prev() {
 
 // Cancel cancels the search operation
 func (sm *SearchManager) Cancel() {
-	sm.Results = []int{}
+	sm.Results = []Match{}
 	sm.CurrentIndex = 0
 	sm.LastTerm = ""
 	fmt.Println("Search cancelled")
 }
 
-// GetCurrentMatch returns the current match position
+// GetCurrentMatch returns the current match's start position
 func (sm *SearchManager) GetCurrentMatch() int {
 	if sm.CurrentIndex >= 0 && sm.CurrentIndex < len(sm.Results) {
-		return sm.Results[sm.CurrentIndex]
+		return sm.Results[sm.CurrentIndex].Start
 	}
 	return -1
 }
@@ -7,11 +7,20 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
+	"github.com/gdamore/tcell/v2"
+
+	"letsgo-editor/buffer"
 	"letsgo-editor/featu"
+	"letsgo-editor/history"
 	"letsgo-editor/keybinds"
 	"letsgo-editor/logic"
+	"letsgo-editor/search/fuzzy"
+	"letsgo-editor/syntax"
 	"letsgo-editor/ui"
 )
 
@@ -25,29 +34,44 @@ type EditorState struct {
 	EditManager    *logic.EditManager    // Edit operations
 	SearchManager  *logic.SearchManager  // Search/replace
 	KeybindManager *keybinds.KeybindManager // Keybindings
+	KeyResolver    *keybinds.KeyResolver    // Chord/prefix key dispatch
+	History        *history.History      // Persistent command/search history
 	FontEdit       *featu.FontEdit       // Font management
 	NumberLine     *featu.NumberLine     // Line numbers
 	MainUI         *ui.MainUI            // Main UI
-	Theme          *ui.Theme             // Current theme
+	ThemeManager   *ui.ThemeManager      // Current theme, load/save
 	Running        bool                  // Application state
 }
 
 // Initialize creates a new editor state with all components
 func (state *EditorState) Initialize() {
 	state.BuffersManager = &logic.BuffersManager{
-		Buffers:      []logic.Buffer{},
+		Buffers:      []*logic.Buffer{},
 		ActiveBuffer: 0,
 	}
-	state.EditManager = &logic.EditManager{}
+	state.EditManager = &logic.EditManager{SyntaxRegistry: syntax.NewRegistry()}
+	state.EditManager.SyntaxRegistry.LoadDir("syntax/langs")
 	state.SearchManager = &logic.SearchManager{
 		CaseSensitive: false,
 		UseRegex:      false,
-		Results:       []int{},
+		Results:       []logic.Match{},
 		CurrentIndex:  0,
 	}
 	state.KeybindManager = &keybinds.KeybindManager{
 		Bindings: []keybinds.Keybinding{},
 	}
+
+	historyPath, err := history.DefaultPath()
+	if err != nil {
+		fmt.Printf("Unable to resolve history path (%v), history won't persist across runs\n", err)
+	}
+	state.History = history.New(historyPath, history.DefaultMaxEntries)
+	if historyPath != "" {
+		if err := state.History.Load(); err != nil {
+			fmt.Printf("Error loading history: %v\n", err)
+		}
+	}
+
 	state.FontEdit = &featu.FontEdit{FontSize: 14}
 	state.NumberLine = &featu.NumberLine{
 		TotalLines:   1,
@@ -56,18 +80,36 @@ func (state *EditorState) Initialize() {
 	}
 	
 	// Initialize theme
-	state.Theme = &ui.Theme{
-		BackgroundColor: "#1e1e1e",
-		ForegroundColor: "#d4d4d4",
-		AccentColor:     "#007acc",
-		MenuBarColor:    "#2d2d30",
-		StatusBarColor:  "#007acc",
-		TextAreaColor:   "#1e1e1e",
-		NumberLineColor: "#858585",
+	state.ThemeManager = &ui.ThemeManager{
+		Current: ui.Theme{
+			BackgroundColor: "#1e1e1e",
+			ForegroundColor: "#d4d4d4",
+			AccentColor:     "#007acc",
+			MenuBarColor:    "#2d2d30",
+			StatusBarColor:  "#007acc",
+			TextAreaColor:   "#1e1e1e",
+			NumberLineColor: "#858585",
+			KeywordColor:    "#569cd6",
+			StringColor:     "#ce9178",
+			CommentColor:    "#6a9955",
+			NumberColor:     "#b5cea8",
+		},
 	}
-	
+	if themePath, err := ui.DefaultThemePath(); err == nil {
+		if err := state.ThemeManager.Load(themePath); err != nil {
+			fmt.Printf("Error loading theme: %v\n", err)
+		}
+	}
+
+	// Restore the previous session's split layout, if any was saved.
+	if layoutPath, err := logic.DefaultLayoutPath(); err == nil {
+		if _, err := state.BuffersManager.LoadLayout(layoutPath); err != nil {
+			fmt.Printf("Error loading layout: %v\n", err)
+		}
+	}
+
 	// Initialize UI with canvas
-	canvas := &ui.Canvas{Windows: []ui.Window{}, Width: 120, Height: 40}
+	canvas := &ui.Canvas{Windows: []ui.Window{}, Width: 120, Height: 40, Theme: &state.ThemeManager.Current}
 	state.MainUI = &ui.MainUI{
 		Canvas:    canvas,
 		MenuBar:   ui.MenuBar{},
@@ -83,46 +125,104 @@ func (state *EditorState) Initialize() {
 	state.loadDefaultKeybindings()
 }
 
-// loadDefaultKeybindings sets up the default keyboard shortcuts
+// defaultKeybindsPath returns the per-user keybindings file location,
+// alongside the history and theme files under ~/.config/letsgo-editor.
+func defaultKeybindsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error resolving home directory: %v", err)
+	}
+	return filepath.Join(home, ".config", "letsgo-editor", "keybindings.json"), nil
+}
+
+// loadDefaultKeybindings sets up the default keyboard shortcuts, seeding
+// both KeybindManager and KeyResolver from KeybindManager.ResetToDefault so
+// the two can't drift apart into disagreeing about what's bound at startup.
 func (state *EditorState) loadDefaultKeybindings() {
-	defaultBindings := []keybinds.Keybinding{
-		{Action: "new_file", Key: "Ctrl+N"},
-		{Action: "open_file", Key: "Ctrl+O"},
-		{Action: "save_file", Key: "Ctrl+S"},
-		{Action: "save_all", Key: "Ctrl+Shift+S"},
-		{Action: "close_file", Key: "Ctrl+W"},
-		{Action: "quit", Key: "Ctrl+Q"},
-		{Action: "undo", Key: "Ctrl+Z"},
-		{Action: "redo", Key: "Ctrl+Y"},
-		{Action: "cut", Key: "Ctrl+X"},
-		{Action: "copy", Key: "Ctrl+C"},
-		{Action: "paste", Key: "Ctrl+V"},
-		{Action: "select_all", Key: "Ctrl+A"},
-		{Action: "search", Key: "Ctrl+F"},
-		{Action: "replace", Key: "Ctrl+H"},
-		{Action: "font_increase", Key: "Ctrl++"},
-		{Action: "font_decrease", Key: "Ctrl+-"},
-		{Action: "go_to_line_start", Key: "Home"},
-		{Action: "go_to_line_end", Key: "End"},
-		{Action: "go_to_file_start", Key: "Ctrl+Home"},
-		{Action: "go_to_file_end", Key: "Ctrl+End"},
-		{Action: "next_word", Key: "Ctrl+Right"},
-		{Action: "prev_word", Key: "Ctrl+Left"},
-		{Action: "delete_word", Key: "Ctrl+Delete"},
-		{Action: "delete_line", Key: "Ctrl+Shift+K"},
-		{Action: "next_line", Key: "Down"},
-		{Action: "prev_line", Key: "Up"},
-		{Action: "next_char", Key: "Right"},
-		{Action: "prev_char", Key: "Left"},
+	state.KeybindManager.ResetToDefault()
+	state.KeyResolver = keybinds.NewKeyResolver(state.KeybindManager.Bindings)
+}
+
+// DispatchKey feeds a single raw key token (e.g. "Ctrl+X") through the
+// chord resolver and, once it resolves to an action, runs that action the
+// same way HandleInput does for its matching command string. While a
+// prefix is pending it mirrors the in-progress chord into the StatusBar and
+// shows the hint bar's completions of it, so the user sees both before
+// completing or abandoning it.
+func (state *EditorState) DispatchKey(key string) {
+	if state.MainUI.Supervisor.IsOpen() {
+		switch key {
+		case "Up":
+			state.MainUI.MenuMoveSelection(-1)
+			return
+		case "Down":
+			state.MainUI.MenuMoveSelection(1)
+			return
+		case "Enter":
+			if action := state.MainUI.MenuActivate(); action != "" {
+				state.HandleInput(action)
+			}
+			return
+		case "Esc":
+			state.MainUI.MenuBack()
+			return
+		}
+		// Any other key closes the popup before falling through to its
+		// usual handling, the way clicking outside it does.
+		state.MainUI.CloseMenu()
+	}
+
+	action, pending, reset := state.KeyResolver.Feed(key)
+
+	if pending {
+		state.MainUI.StatusBar.PendingPrefix = state.KeyResolver.Pending()
+		state.MainUI.ShowHintBar(state.KeyResolver.Completions())
+		return
+	}
+
+	state.MainUI.StatusBar.PendingPrefix = ""
+	state.MainUI.HideHintBar()
+
+	if reset {
+		// The key didn't continue the pending chord; re-feed it against the
+		// now-reset resolver since it may start a chord of its own.
+		state.DispatchKey(key)
+		return
+	}
+
+	if action != "" {
+		state.HandleInput(action)
 	}
-	
-	state.KeybindManager.Bindings = defaultBindings
 }
 
-// HandleInput processes user input and executes corresponding actions
+// HandleInput processes user input and executes corresponding actions.
+// input is either a keybinding Action (e.g. "save_file", fed by
+// DispatchKey from a raw key event) or one of the legacy typed-word
+// synonyms still accepted from the line-mode fallback prompt (e.g.
+// "save", "quit", "q").
 func (state *EditorState) HandleInput(input string) {
 	input = strings.TrimSpace(input)
-	
+
+	// Macro recording/playback are handled before the Record call below
+	// so neither the toggle/play trigger itself nor a bound "@macro"
+	// replay is captured as a step inside whatever macro is currently
+	// being recorded - the actions *they* dispatch still flow through
+	// HandleInput individually and are recorded normally.
+	if name, ok := keybinds.MacroName(input); ok {
+		state.playMacro(name)
+		return
+	}
+	switch input {
+	case "toggle_macro_recording":
+		state.toggleMacroRecording()
+		return
+	case "play_last_macro":
+		state.playMacro(state.KeybindManager.LastMacro())
+		return
+	}
+
+	state.KeybindManager.Record(keybinds.Action{Name: input})
+
 	// Handle special commands
 	switch input {
 	case "quit", "exit", "q":
@@ -131,45 +231,467 @@ func (state *EditorState) HandleInput(input string) {
 	case "help", "h":
 		state.showHelp()
 		return
-	case "new":
+	case "new_file", "new":
 		state.EditManager.NewFile()
-		fmt.Println("New file created")
+		state.MainUI.StatusBar.LineEnding = string(state.EditManager.LineEnding)
 		return
-	case "save":
+	case "open_file":
+		fmt.Println("Open file: use line-mode 'open <filename>' until a file-open dialog exists")
+		return
+	case "save_file", "save":
 		state.EditManager.SaveFile()
-		fmt.Println("File saved")
+		return
+	case "save_all":
+		state.EditManager.SaveAll()
+		return
+	case "close_file":
+		state.EditManager.CloseFile()
+		return
+	case "undo":
+		state.EditManager.Undo()
+		return
+	case "redo":
+		state.EditManager.Redo()
+		return
+	case "cut", "copy", "paste":
+		fmt.Printf("%s: clipboard integration not implemented yet\n", input)
+		return
+	case "select_all":
+		state.EditManager.SelectAll()
 		return
 	case "search":
-		fmt.Print("Enter search term: ")
-		reader := bufio.NewReader(os.Stdin)
-		term, _ := reader.ReadString('\n')
-		term = strings.TrimSpace(term)
-		if term != "" {
-			state.SearchManager.Search("", term) // Placeholder text
-			fmt.Printf("Searching for: %s\n", term)
-		}
+		state.runFindBar()
+		return
+	case "replace":
+		state.runFindBar()
+		return
+	case "show_help":
+		state.runHelpPane()
+		return
+	case "file_palette":
+		state.runFilePalette()
+		return
+	case "command_palette":
+		state.runCommandPalette()
+		return
+	case "set_line_ending_lf":
+		state.EditManager.SetLineEnding(buffer.LF)
+		state.MainUI.StatusBar.LineEnding = string(state.EditManager.LineEnding)
+		return
+	case "set_line_ending_crlf":
+		state.EditManager.SetLineEnding(buffer.CRLF)
+		state.MainUI.StatusBar.LineEnding = string(state.EditManager.LineEnding)
+		return
+	case "go_to_line_start":
+		state.EditManager.GoToStartOfLine()
+		return
+	case "go_to_line_end":
+		state.EditManager.GoToEndOfLine()
+		return
+	case "go_to_file_start":
+		state.EditManager.GoToStartOfFile()
+		return
+	case "go_to_file_end":
+		state.EditManager.GoToEndOfFile()
+		return
+	case "next_word":
+		state.EditManager.GoToNextWord()
 		return
-	case "font+":
+	case "prev_word":
+		state.EditManager.GoToPreviousWord()
+		return
+	case "delete_word":
+		state.EditManager.DeleteWord()
+		return
+	case "delete_line":
+		state.EditManager.DeleteLine()
+		return
+	case "next_line":
+		state.EditManager.GoToNextLine()
+		return
+	case "prev_line":
+		state.EditManager.GoToPreviousLine()
+		return
+	case "next_char":
+		state.EditManager.GoForwardChar()
+		return
+	case "prev_char":
+		state.EditManager.GoBackwardChar()
+		return
+	case "select_left":
+		state.EditManager.SelectLeft()
+		return
+	case "select_right":
+		state.EditManager.SelectRight()
+		return
+	case "select_up":
+		state.EditManager.SelectUp()
+		return
+	case "select_down":
+		state.EditManager.SelectDown()
+		return
+	case "select_word_left":
+		state.EditManager.SelectWordLeft()
+		return
+	case "select_word_right":
+		state.EditManager.SelectWordRight()
+		return
+	case "font_increase", "font+":
 		state.FontEdit.Increase()
-		fmt.Printf("Font size increased to: %d\n", state.FontEdit.FontSize)
 		return
-	case "font-":
+	case "font_decrease", "font-":
 		state.FontEdit.Decrease()
-		fmt.Printf("Font size decreased to: %d\n", state.FontEdit.FontSize)
 		return
 	case "theme":
-		fmt.Printf("Current theme: Background=%s, Foreground=%s\n", 
-			state.Theme.BackgroundColor, state.Theme.ForegroundColor)
+		fmt.Printf("Current theme: Background=%s, Foreground=%s\n",
+			state.ThemeManager.Current.BackgroundColor, state.ThemeManager.Current.ForegroundColor)
+		return
+	case "open_settings", "settings":
+		state.runSettingsWindow()
 		return
 	case "status":
 		state.showStatus()
 		return
 	}
-	
+
 	// If no special command matched, treat as regular text input
 	fmt.Printf("Input received: %s\n", input)
 }
 
+// runFindBar shows the find bar and drives it until the user accepts or
+// cancels. Each line the user types replaces the query and (when
+// Incremental is on) reruns the search immediately, mirroring the
+// keystroke-driven behavior the real find bar will have once input is read
+// raw instead of line-buffered. Enter on an empty line moves to the next
+// match, "p" moves to the previous match (Shift+Enter stand-in), and
+// "esc"/an empty query followed by Esc closes the bar.
+func (state *EditorState) runFindBar() {
+	state.MainUI.ShowFindBar()
+	state.MainUI.FindBar.Incremental = true
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Printf("Find: %s", state.MainUI.FindBar.Query)
+		fmt.Print("> ")
+		line, _ := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\n")
+
+		switch line {
+		case "esc":
+			state.SearchManager.Cancel()
+			state.MainUI.HideFindBar()
+			return
+		case "":
+			state.SearchManager.Next()
+		case "p":
+			state.SearchManager.Prev()
+		default:
+			state.MainUI.UpdateFindQuery(line)
+			state.History.Add(line)
+			state.SearchManager.CaseSensitive = state.MainUI.FindBar.CaseSensitive
+			state.SearchManager.UseRegex = state.MainUI.FindBar.Regex
+			state.SearchManager.WholeWord = state.MainUI.FindBar.WholeWord
+			if state.MainUI.FindBar.Incremental {
+				state.SearchManager.Search(state.currentBufferContent(), line)
+				if pos := state.SearchManager.GetCurrentMatch(); pos >= 0 {
+					fmt.Printf("Scrolled to match at offset %d\n", pos)
+				}
+			}
+		}
+	}
+}
+
+// currentBufferContent returns the content of the active buffer, or the
+// empty string if no buffer is open.
+func (state *EditorState) currentBufferContent() string {
+	bm := state.BuffersManager
+	if bm == nil || len(bm.Buffers) == 0 {
+		return ""
+	}
+	if bm.ActiveBuffer < 0 || bm.ActiveBuffer >= len(bm.Buffers) {
+		return ""
+	}
+	return bm.Buffers[bm.ActiveBuffer].String()
+}
+
+// runHelpPane shows the searchable keybinding overlay bound to F1. Each
+// line the user types narrows the list by fuzzy-matching action names and
+// keys; an empty line closes the pane.
+func (state *EditorState) runHelpPane() {
+	pane := keybinds.NewHelpPane(state.KeybindManager)
+	state.MainUI.ShowHelpPane(pane.Filter())
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Print("Search keybindings (blank to close)> ")
+		line, _ := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\n")
+
+		if line == "" {
+			state.MainUI.HideHelpPane()
+			return
+		}
+
+		pane.Query = line
+		rows := pane.Filter()
+		state.MainUI.UpdateHelpPane(line, rows)
+		for _, row := range rows {
+			fmt.Printf("  %-24s %s\n", row.Action, row.Key)
+		}
+	}
+}
+
+// runFilePalette shows the palette in file-finder mode and streams in
+// candidates from a goroutine that walks the working directory, so the
+// list keeps growing while the user is already typing a query. Each line
+// the user types replaces the query and rescores the candidates gathered
+// so far; an empty line accepts the highlighted file and opens it, "esc"
+// cancels, "n"/"p" move the highlight (Down/Up stand-ins for the
+// line-buffered fallback).
+func (state *EditorState) runFilePalette() {
+	state.MainUI.ShowPalette("file")
+
+	var mu sync.Mutex
+	var candidates []string
+	go func() {
+		filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+			if err != nil || info == nil || info.IsDir() {
+				return nil
+			}
+			mu.Lock()
+			candidates = append(candidates, path)
+			mu.Unlock()
+			return nil
+		})
+	}()
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		mu.Lock()
+		state.MainUI.SetPaletteResults(state.MainUI.Palette.Query, scoredPaletteItems(state.MainUI.Palette.Query, candidates))
+		mu.Unlock()
+
+		fmt.Print("Find file> ")
+		line, _ := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\n")
+
+		switch line {
+		case "esc":
+			state.MainUI.HidePalette()
+			return
+		case "":
+			item, ok := state.MainUI.SelectedPaletteItem()
+			state.MainUI.HidePalette()
+			if ok {
+				state.EditManager.OpenFile(item.Label)
+			}
+			return
+		case "n":
+			state.MainUI.MovePaletteSelection(1)
+		case "p":
+			state.MainUI.MovePaletteSelection(-1)
+		default:
+			state.MainUI.Palette.Query = line
+			state.MainUI.Palette.Selected = 0
+		}
+	}
+}
+
+// runCommandPalette shows the palette in command-palette mode, sourced
+// from the action names in KeybindManager.Bindings rather than a file
+// walk, and runs the selected action through HandleInput the same way a
+// bound key would.
+func (state *EditorState) runCommandPalette() {
+	state.MainUI.ShowPalette("command")
+
+	actions := make([]string, 0, len(state.KeybindManager.Bindings))
+	seen := map[string]bool{}
+	for _, b := range state.KeybindManager.Bindings {
+		if !seen[b.Action] {
+			seen[b.Action] = true
+			actions = append(actions, b.Action)
+		}
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		state.MainUI.SetPaletteResults(state.MainUI.Palette.Query, scoredPaletteItems(state.MainUI.Palette.Query, actions))
+
+		fmt.Print("Command> ")
+		line, _ := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\n")
+
+		switch line {
+		case "esc":
+			state.MainUI.HidePalette()
+			return
+		case "":
+			item, ok := state.MainUI.SelectedPaletteItem()
+			state.MainUI.HidePalette()
+			if ok {
+				state.History.Add(item.Label)
+				state.HandleInput(item.Label)
+			}
+			return
+		case "n":
+			state.MainUI.MovePaletteSelection(1)
+		case "p":
+			state.MainUI.MovePaletteSelection(-1)
+		default:
+			state.MainUI.Palette.Query = line
+			state.MainUI.Palette.Selected = 0
+		}
+	}
+}
+
+// runSettingsWindow drives the modal settings dialog: "tab"/"shift+tab"
+// switch tabs, "n"/"p" move the row selection, blank activates the
+// selected row (toggles a checkbox, cycles a combobox/color picker, or
+// starts a "record key" capture on the Controls tab), and "esc" closes
+// the window. While a key capture is pending, the next line typed is used
+// verbatim as the new key ("" clears the binding, "esc" cancels).
+// Changes already took effect through the bound pointers by the time the
+// window closes; "save" also persists them via ThemeManager.Save,
+// KeybindManager.SaveKeybindings, and BuffersManager.SaveLayout.
+func (state *EditorState) runSettingsWindow() {
+	state.MainUI.ShowSettingsWindow(state.ThemeManager, state.KeybindManager)
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		fmt.Print(state.MainUI.Settings.Render())
+
+		if state.MainUI.Settings.Recording {
+			fmt.Print("Press a key (blank clears, esc cancels)> ")
+		} else {
+			fmt.Print("Settings (tab/shift+tab, n/p, blank=activate, save, esc)> ")
+		}
+
+		line, _ := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\n")
+
+		if state.MainUI.Settings.Recording {
+			if line == "esc" {
+				state.MainUI.Settings.Recording = false
+			} else {
+				state.MainUI.Settings.CaptureKey(line)
+			}
+			state.MainUI.RefreshSettingsWindow()
+			continue
+		}
+
+		switch line {
+		case "esc":
+			state.MainUI.HideSettingsWindow()
+			return
+		case "tab":
+			state.MainUI.Settings.NextTab()
+		case "shift+tab":
+			state.MainUI.Settings.PrevTab()
+		case "n":
+			state.MainUI.Settings.MoveSelection(1)
+		case "p":
+			state.MainUI.Settings.MoveSelection(-1)
+		case "save":
+			if themePath, err := ui.DefaultThemePath(); err == nil {
+				if err := state.ThemeManager.Save(themePath); err != nil {
+					fmt.Printf("Error saving theme: %v\n", err)
+				}
+			}
+			if keybindsPath, err := defaultKeybindsPath(); err == nil {
+				if err := state.KeybindManager.SaveKeybindings(keybindsPath); err != nil {
+					fmt.Printf("Error saving keybindings: %v\n", err)
+				}
+			}
+			if layoutPath, err := logic.DefaultLayoutPath(); err == nil {
+				if err := state.BuffersManager.SaveLayout(layoutPath); err != nil {
+					fmt.Printf("Error saving layout: %v\n", err)
+				}
+			}
+		case "":
+			state.MainUI.Settings.Activate()
+		}
+		state.MainUI.RefreshSettingsWindow()
+	}
+}
+
+// scoredPaletteItems scores every candidate against query with
+// search/fuzzy.Score and returns the matches sorted best-first. An empty
+// query matches everything with no highlighted positions, preserving
+// candidate order, so the palette shows the full list before the user
+// starts typing.
+func scoredPaletteItems(query string, candidates []string) []ui.PaletteItem {
+	if query == "" {
+		items := make([]ui.PaletteItem, len(candidates))
+		for i, c := range candidates {
+			items[i] = ui.PaletteItem{Label: c}
+		}
+		return items
+	}
+
+	type scoredItem struct {
+		item  ui.PaletteItem
+		score int
+	}
+	scored := make([]scoredItem, 0, len(candidates))
+	for _, c := range candidates {
+		score, positions := fuzzy.Score(query, c)
+		if positions == nil {
+			continue
+		}
+		scored = append(scored, scoredItem{item: ui.PaletteItem{Label: c, Positions: positions}, score: score})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	items := make([]ui.PaletteItem, len(scored))
+	for i, s := range scored {
+		items[i] = s.item
+	}
+	return items
+}
+
+// defaultMacroName is the single recording slot toggle_macro_recording
+// captures into, mirroring micro's ToggleMacro/PlayMacro (one macro at a
+// time, no naming prompt) - a macro bound to a key via
+// keybinds.MacroAction can still be given any other name by editing the
+// keybindings file directly.
+const defaultMacroName = "last"
+
+// toggleMacroRecording starts or stops capturing a macro into
+// defaultMacroName
+func (state *EditorState) toggleMacroRecording() {
+	if state.KeybindManager.Recording() {
+		state.KeybindManager.StopRecording()
+		fmt.Println("Macro recording stopped")
+		return
+	}
+	state.KeybindManager.StartRecording(defaultMacroName)
+	fmt.Println("Macro recording started")
+}
+
+// playMacro replays the named macro by re-dispatching each recorded
+// action through HandleInput, bracketed in a single undo group on the
+// active document so the whole replay undoes in one step.
+func (state *EditorState) playMacro(name string) {
+	if name == "" {
+		fmt.Println("No macro to play")
+		return
+	}
+
+	if state.EditManager.Doc != nil {
+		state.EditManager.Doc.BeginUndoGroup()
+		defer state.EditManager.Doc.EndUndoGroup()
+	}
+
+	if !state.KeybindManager.Play(name, func(a keybinds.Action) {
+		state.HandleInput(a.Name)
+	}) {
+		fmt.Printf("No macro named %q\n", name)
+	}
+}
+
 // showHelp displays available commands
 func (state *EditorState) showHelp() {
 	fmt.Println("\n=== LetsGo Editor Help ===")
@@ -200,57 +722,274 @@ func (state *EditorState) showStatus() {
 	fmt.Printf("Total lines: %d\n", state.NumberLine.TotalLines)
 	fmt.Printf("Search case sensitive: %t\n", state.SearchManager.CaseSensitive)
 	fmt.Printf("Search regex mode: %t\n", state.SearchManager.UseRegex)
+	fmt.Printf("Line ending: %s\n", state.EditManager.LineEnding)
 	fmt.Println()
 }
 
-// Render updates the display
+// Render updates the display. In line-mode (no real terminal screen) it
+// prints a small text banner above the canvas on every frame; in screen
+// mode the tcell-drawn canvas is the entire display, so the banner is
+// skipped and MainUI.Render draws straight onto the screen.
 func (state *EditorState) Render() {
-	// Clear screen (simple implementation)
-	fmt.Print("\033[2J\033[H")
-	
-	// Render UI
-	fmt.Println("=== LetsGo Editor ===")
-	fmt.Printf("Font Size: %d | Line: %d/%d | Buffers: %d\n", 
-		state.FontEdit.FontSize, 
-		state.NumberLine.CurrentLine, 
-		state.NumberLine.TotalLines,
-		len(state.BuffersManager.Buffers))
-	fmt.Println("Type 'help' for commands, 'quit' to exit")
-	fmt.Println("---")
-	
-	// Render main UI components
+	if state.MainUI.Canvas.Screen == nil {
+		fmt.Print("\033[2J\033[H")
+		fmt.Println("=== LetsGo Editor ===")
+		fmt.Printf("Font Size: %d | Line: %d/%d | Buffers: %d\n",
+			state.FontEdit.FontSize,
+			state.NumberLine.CurrentLine,
+			state.NumberLine.TotalLines,
+			len(state.BuffersManager.Buffers))
+		fmt.Println("Type 'help' for commands, 'quit' to exit")
+		fmt.Println("---")
+	}
+
+	state.syncTextArea()
 	state.MainUI.Render()
 }
 
+// syncTextArea refreshes the Editor window's displayed content, cursor
+// position, and syntax highlighting from the active document. Spans are
+// recomputed from EditManager.Doc on every call rather than cached, so
+// edits are reflected on the very next frame with no separate
+// invalidation step.
+func (state *EditorState) syncTextArea() {
+	doc := state.EditManager.Doc
+	if doc == nil {
+		return
+	}
+
+	state.MainUI.TextArea.Content = doc.String()
+	state.MainUI.TextArea.CursorLine = doc.Cursor.Loc.Line + 1
+	state.MainUI.TextArea.CursorColumn = doc.Cursor.Loc.Col + 1
+
+	highlights := state.bufferHighlights(doc)
+
+	state.MainUI.Splits = state.splitPaneViews(highlights)
+	if len(state.MainUI.Splits) == 0 {
+		state.MainUI.Canvas.UpdateWindowHighlights("Editor", highlights)
+	}
+}
+
+// bufferHighlights computes buf's per-line syntax spans, shifted to line up
+// with the "%3d | " line-number prefix renderTextArea/formatPaneContent add
+// when ShowNumbers is on.
+func (state *EditorState) bufferHighlights(buf *logic.Buffer) [][]syntax.Span {
+	prefixLen := 0
+	if state.MainUI.TextArea.ShowNumbers {
+		prefixLen = len(fmt.Sprintf("%3d | ", 0))
+	}
+
+	highlights := make([][]syntax.Span, buf.LineCount())
+	for i := range highlights {
+		spans := buf.HighlightLine(i)
+		if prefixLen > 0 {
+			shifted := make([]syntax.Span, len(spans))
+			for j, s := range spans {
+				shifted[j] = syntax.Span{Start: s.Start + prefixLen, End: s.End + prefixLen, Color: s.Color}
+			}
+			spans = shifted
+		}
+		highlights[i] = spans
+	}
+	return highlights
+}
+
+// splitPaneViews flattens BuffersManager's split tree (if any split has
+// been made) into ui.SplitPane values ready for MainUI to render, one per
+// leaf pane, sized against the "Editor" window's current rect. The
+// lowest-ID pane always maps to "Editor" itself so a single-pane layout
+// renders identically to the pre-split editor; returns nil (leaving the
+// editor as the single plain "Editor" window) until the first split.
+// focusedHighlights is the already-computed highlight set for the focused
+// pane's content (the live EditManager.Doc); every other pane recomputes
+// its own from whichever buffer it's showing.
+func (state *EditorState) splitPaneViews(focusedHighlights [][]syntax.Span) []ui.SplitPane {
+	bm := state.BuffersManager
+	if bm == nil || bm.Root == nil {
+		return nil
+	}
+
+	editorWin := state.MainUI.Canvas.FindWindow("Editor")
+	if editorWin == nil {
+		return nil
+	}
+
+	rects := bm.Layout(editorWin.Width, editorWin.Height)
+	sort.Slice(rects, func(i, j int) bool { return rects[i].PaneID < rects[j].PaneID })
+
+	views := make([]ui.SplitPane, 0, len(rects))
+	for i, r := range rects {
+		pane := bm.Pane(r.PaneID)
+		if pane == nil {
+			continue
+		}
+
+		title := "Editor"
+		if i > 0 {
+			title = fmt.Sprintf("Editor#%d", r.PaneID)
+		}
+
+		// The active document (EditManager.Doc) is the only buffer
+		// content the editor actually keeps live right now, so every
+		// pane shows it by default - the same thing tmux shows you in a
+		// freshly split pane. A pane that's been handed a different
+		// buffer via MoveBufferToPane shows that buffer's own text (and
+		// its own highlights) instead, though it won't live-update past
+		// what's on disk.
+		content := state.currentBufferContent()
+		paneHighlights := focusedHighlights
+		if idx := pane.ActiveBufferIndex(); idx >= 0 && idx < len(bm.Buffers) && idx != bm.ActiveBuffer {
+			content = bm.Buffers[idx].String()
+			paneHighlights = state.bufferHighlights(bm.Buffers[idx])
+		}
+
+		cursorLine, cursorColumn := pane.CursorLine, pane.CursorColumn
+		if r.PaneID == bm.Focused {
+			cursorLine, cursorColumn = state.MainUI.TextArea.CursorLine, state.MainUI.TextArea.CursorColumn
+			pane.CursorLine, pane.CursorColumn = cursorLine, cursorColumn
+		}
+
+		views = append(views, ui.SplitPane{
+			Title:        title,
+			X:            editorWin.X + r.X,
+			Y:            editorWin.Y + r.Y,
+			Width:        r.Width,
+			Height:       r.Height,
+			Content:      content,
+			CursorLine:   cursorLine,
+			CursorColumn: cursorColumn,
+			ScrollOffset: pane.ScrollOffset,
+			Highlights:   paneHighlights,
+			Focused:      r.PaneID == bm.Focused,
+		})
+	}
+	return views
+}
+
+// runScreenMode drives the editor with a real tcell.Screen: every
+// keystroke arrives as a raw event, is translated to the same key tokens
+// the KeyResolver already understands, and is dispatched through
+// DispatchKey instead of being parsed as typed words. Left-clicks are
+// routed through MainUI.HandleMouseClick, which opens/navigates/closes
+// MenuBar and Toolbar popups (see ui.Supervisor); InitScreen already
+// enables mouse reporting for us.
+func (state *EditorState) runScreenMode() {
+	defer state.MainUI.Canvas.CloseScreen()
+
+	for state.Running {
+		state.Render()
+
+		switch ev := state.MainUI.Canvas.Screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			state.MainUI.Canvas.SyncSize()
+			if state.MainUI.Panes != nil {
+				state.MainUI.Panes.Update()
+			}
+			state.MainUI.Canvas.Screen.Sync()
+		case *tcell.EventKey:
+			state.DispatchKey(translateKeyEvent(ev))
+		case *tcell.EventMouse:
+			if ev.Buttons()&tcell.Button1 != 0 {
+				x, y := ev.Position()
+				if action := state.MainUI.HandleMouseClick(x, y); action != "" {
+					state.HandleInput(action)
+				}
+			}
+		}
+	}
+}
+
+// runLineMode is the original fallback loop: it reads whole lines from
+// stdin and parses them as typed commands. Used when InitScreen fails,
+// e.g. stdout isn't a real terminal.
+func (state *EditorState) runLineMode() {
+	editor := history.NewLineEditor(bufio.NewReader(os.Stdin), state.History)
+
+	for state.Running {
+		state.Render()
+
+		input := editor.ReadLine("> ")
+		state.History.Add(input)
+		state.HandleInput(input)
+	}
+}
+
+// ctrlKeyNames maps tcell's dedicated Ctrl+<letter> key constants to the
+// "Ctrl+X"-style tokens used throughout KeybindManager/KeyResolver.
+var ctrlKeyNames = map[tcell.Key]string{
+	tcell.KeyCtrlA: "Ctrl+A", tcell.KeyCtrlC: "Ctrl+C", tcell.KeyCtrlF: "Ctrl+F",
+	tcell.KeyCtrlH: "Ctrl+H", tcell.KeyCtrlN: "Ctrl+N", tcell.KeyCtrlO: "Ctrl+O",
+	tcell.KeyCtrlP: "Ctrl+P", tcell.KeyCtrlQ: "Ctrl+Q", tcell.KeyCtrlR: "Ctrl+R",
+	tcell.KeyCtrlS: "Ctrl+S", tcell.KeyCtrlV: "Ctrl+V", tcell.KeyCtrlW: "Ctrl+W",
+	tcell.KeyCtrlX: "Ctrl+X", tcell.KeyCtrlY: "Ctrl+Y", tcell.KeyCtrlZ: "Ctrl+Z",
+}
+
+// namedKeys maps tcell keys with no rune of their own to the token names
+// used by the default keybindings (Home, End, the arrow keys, F1, ...).
+var namedKeys = map[tcell.Key]string{
+	tcell.KeyEnter: "Enter", tcell.KeyEscape: "Esc", tcell.KeyTab: "Tab",
+	tcell.KeyBackspace: "Backspace", tcell.KeyBackspace2: "Backspace",
+	tcell.KeyDelete: "Delete",
+	tcell.KeyUp:     "Up", tcell.KeyDown: "Down", tcell.KeyLeft: "Left", tcell.KeyRight: "Right",
+	tcell.KeyHome: "Home", tcell.KeyEnd: "End", tcell.KeyF1: "F1",
+}
+
+// translateKeyEvent converts a raw tcell key event into a key token like
+// "Ctrl+X", "Ctrl+Alt+L" or "Right" that KeyResolver/KeybindManager can
+// match against the configured bindings.
+func translateKeyEvent(ev *tcell.EventKey) string {
+	if name, ok := ctrlKeyNames[ev.Key()]; ok {
+		return withModifiers(ev.Modifiers()&^tcell.ModCtrl, name)
+	}
+	if name, ok := namedKeys[ev.Key()]; ok {
+		return withModifiers(ev.Modifiers(), name)
+	}
+	if ev.Key() == tcell.KeyRune {
+		return withModifiers(ev.Modifiers(), string(ev.Rune()))
+	}
+	if name, ok := tcell.KeyNames[ev.Key()]; ok {
+		return withModifiers(ev.Modifiers(), name)
+	}
+	return withModifiers(ev.Modifiers(), fmt.Sprintf("Key%d", ev.Key()))
+}
+
+// withModifiers prepends the "Ctrl+"/"Alt+"/"Shift+" tokens the modifier
+// mask carries in front of name, in the same order the default bindings
+// use them (e.g. "Ctrl+Alt+L").
+func withModifiers(mod tcell.ModMask, name string) string {
+	var b strings.Builder
+	if mod&tcell.ModCtrl != 0 {
+		b.WriteString("Ctrl+")
+	}
+	if mod&tcell.ModAlt != 0 {
+		b.WriteString("Alt+")
+	}
+	if mod&tcell.ModShift != 0 {
+		b.WriteString("Shift+")
+	}
+	b.WriteString(name)
+	return b.String()
+}
+
 // main initializes the editor and enters the main event loop
 func main() {
 	// Initialize the editor state
 	var state EditorState
 	state.Initialize()
-	
-	fmt.Println("LetsGo Editor - A modular, keyboard-driven text editor")
-	fmt.Println("Following principles: keyboard navigation, modularity, simplicity")
-	fmt.Println("Type 'help' for available commands")
-	
-	// Create input reader
-	reader := bufio.NewReader(os.Stdin)
-	
-	// Main event loop - completely keyboard driven
-	for state.Running {
-		// Render the current state
-		state.Render()
-		
-		// Get user input
-		fmt.Print("> ")
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Printf("Error reading input: %v\n", err)
-			continue
-		}
-		
-		// Handle the input
-		state.HandleInput(input)
+
+	if err := state.MainUI.Canvas.InitScreen(); err != nil {
+		fmt.Printf("Unable to start terminal UI (%v), falling back to line mode\n", err)
+		fmt.Println("LetsGo Editor - A modular, keyboard-driven text editor")
+		fmt.Println("Following principles: keyboard navigation, modularity, simplicity")
+		fmt.Println("Type 'help' for available commands")
+		state.runLineMode()
+	} else {
+		state.runScreenMode()
 	}
-	
+
+	if err := state.History.Save(); err != nil {
+		fmt.Printf("Error saving history: %v\n", err)
+	}
+
 	fmt.Println("LetsGo Editor closed. Goodbye!")
 }
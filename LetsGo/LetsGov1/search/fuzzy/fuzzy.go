@@ -0,0 +1,111 @@
+/*
+fuzzy implements the fzf-style subsequence scorer used by ui.Palette: a
+candidate matches if every rune of the pattern appears in it in order
+(not necessarily contiguous), and the score rewards matches that stay
+close together and land on "meaningful" boundaries (the start of a word,
+a camelCase hump) the way a human would expect fzf's ranking to.
+*/
+package fuzzy
+
+import "unicode"
+
+// Scoring constants, named after fzf's own: a plain match is worth
+// scoreMatch, consecutive matches compound a bonus instead of just adding
+// scoreMatch again, and a gap between two matched runes costs a startup
+// penalty plus a per-rune extension penalty so tighter matches rank higher.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary    = 8 // first rune, or right after a separator
+	bonusCamel       = 8 // lowercase-to-uppercase hump, e.g. "oF" in "openFile"
+	bonusConsecutive = 4 // compounds per extra consecutive matched rune
+	bonusFirstCharMul = 2
+)
+
+// Score reports how well pattern fuzzy-matches candidate, fzf-style: the
+// match is case-insensitive, and the returned score is 0 with a nil
+// positions slice whenever candidate doesn't contain pattern's runes as an
+// ordered subsequence (an empty pattern always scores 0 with no
+// positions). positions holds the matched rune indices into candidate, in
+// order, for the caller to render with an accent color.
+func Score(pattern, candidate string) (int, []int) {
+	p := []rune(pattern)
+	c := []rune(candidate)
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	positions := make([]int, 0, len(p))
+	score := 0
+	consecutive := 0
+	lastMatched := -1
+	pi := 0
+
+	for ci := 0; ci < len(c) && pi < len(p); ci++ {
+		if unicode.ToLower(c[ci]) != unicode.ToLower(p[pi]) {
+			continue
+		}
+
+		bonus := scoreMatch
+		switch {
+		case isBoundary(c, ci):
+			bonus += bonusBoundary
+		case isCamelHump(c, ci):
+			bonus += bonusCamel
+		}
+
+		if lastMatched == ci-1 {
+			consecutive++
+			bonus += consecutive * bonusConsecutive
+		} else {
+			consecutive = 0
+			if lastMatched >= 0 {
+				gap := ci - lastMatched - 1
+				bonus += scoreGapStart + gap*scoreGapExtension
+			}
+		}
+
+		if pi == 0 {
+			bonus *= bonusFirstCharMul
+		}
+
+		score += bonus
+		positions = append(positions, ci)
+		lastMatched = ci
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, nil
+	}
+	return score, positions
+}
+
+// isBoundary reports whether candidate[i] starts a "word": either it's the
+// first rune, or the rune before it is a separator.
+func isBoundary(c []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	return isSeparator(c[i-1])
+}
+
+// isCamelHump reports whether candidate[i] is an uppercase rune directly
+// following a lowercase one, e.g. the "F" in "openFile".
+func isCamelHump(c []rune, i int) bool {
+	if i == 0 {
+		return false
+	}
+	return unicode.IsUpper(c[i]) && unicode.IsLower(c[i-1])
+}
+
+func isSeparator(r rune) bool {
+	switch r {
+	case ' ', '_', '-', '/', '.', '\\':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,176 @@
+/* syntax holds the editor's per-language highlighting rules, modeled on
+phi's LanguageSyntaxConfig: each language is a small JSON document mapping
+file extensions to a keyword list and a few regexes (strings, comments,
+numbers), with colors resolved by name against ui.Theme at render time so
+this package never has to import ui. */
+
+package syntax
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// identifierPattern finds candidate keyword tokens in a line
+var identifierPattern = regexp.MustCompile(`\b[A-Za-z_][A-Za-z0-9_]*\b`)
+
+// Language describes how to highlight one language's source. The
+// *Pattern fields are regexes read from config; Color fields are color
+// names resolved against ui.Theme (e.g. "keyword", "string").
+type Language struct {
+	Name           string   `json:"name"`
+	Extensions     []string `json:"extensions"`
+	Keywords       []string `json:"keywords"`
+	StringPattern  string   `json:"string_pattern"`
+	CommentPattern string   `json:"comment_pattern"`
+	NumberPattern  string   `json:"number_pattern"`
+	KeywordColor   string   `json:"keyword_color"`
+	StringColor    string   `json:"string_color"`
+	CommentColor   string   `json:"comment_color"`
+	NumberColor    string   `json:"number_color"`
+
+	keywords  map[string]bool
+	stringRe  *regexp.Regexp
+	commentRe *regexp.Regexp
+	numberRe  *regexp.Regexp
+}
+
+// Span is a highlighted byte range within one line of source
+type Span struct {
+	Start, End int
+	Color      string // color name, resolved against ui.Theme by the renderer
+}
+
+// compile builds the language's lookup table and regexes after it's been
+// unmarshaled from config
+func (l *Language) compile() {
+	l.keywords = make(map[string]bool, len(l.Keywords))
+	for _, kw := range l.Keywords {
+		l.keywords[kw] = true
+	}
+	if l.StringPattern != "" {
+		l.stringRe = regexp.MustCompile(l.StringPattern)
+	}
+	if l.CommentPattern != "" {
+		l.commentRe = regexp.MustCompile(l.CommentPattern)
+	}
+	if l.NumberPattern != "" {
+		l.numberRe = regexp.MustCompile(l.NumberPattern)
+	}
+}
+
+// Highlight returns the styled spans for a single line of source, sorted
+// by start offset. A language with no rules yields no spans.
+func (l *Language) Highlight(line string) []Span {
+	if l == nil || line == "" {
+		return nil
+	}
+
+	var spans []Span
+
+	if l.commentRe != nil {
+		if loc := l.commentRe.FindStringIndex(line); loc != nil {
+			// Everything after a comment starts is part of it; nothing
+			// else on the line needs classifying.
+			return []Span{{Start: loc[0], End: loc[1], Color: colorOr(l.CommentColor, "comment")}}
+		}
+	}
+
+	if l.stringRe != nil {
+		for _, loc := range l.stringRe.FindAllStringIndex(line, -1) {
+			spans = append(spans, Span{Start: loc[0], End: loc[1], Color: colorOr(l.StringColor, "string")})
+		}
+	}
+
+	if l.numberRe != nil {
+		for _, loc := range l.numberRe.FindAllStringIndex(line, -1) {
+			spans = append(spans, Span{Start: loc[0], End: loc[1], Color: colorOr(l.NumberColor, "number")})
+		}
+	}
+
+	for _, loc := range identifierPattern.FindAllStringIndex(line, -1) {
+		if word := line[loc[0]:loc[1]]; l.keywords[word] {
+			spans = append(spans, Span{Start: loc[0], End: loc[1], Color: colorOr(l.KeywordColor, "keyword")})
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	return spans
+}
+
+func colorOr(name, fallback string) string {
+	if name != "" {
+		return name
+	}
+	return fallback
+}
+
+// Registry maps file extensions to a Language, populated by scanning a
+// directory of per-language JSON config files.
+type Registry struct {
+	languages   []*Language
+	byExtension map[string]*Language
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{byExtension: map[string]*Language{}}
+}
+
+// LoadDir scans dir for *.json language configs and registers each one.
+// A missing dir is not an error - the registry is simply left empty, the
+// same way KeybindManager.LoadKeybindings tolerates a missing file.
+func (r *Registry) LoadDir(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var lang Language
+		if err := json.Unmarshal(data, &lang); err != nil {
+			continue
+		}
+
+		r.Register(&lang)
+	}
+
+	return nil
+}
+
+// Register compiles lang's rules and indexes it by each of its extensions
+func (r *Registry) Register(lang *Language) {
+	lang.compile()
+	r.languages = append(r.languages, lang)
+	for _, ext := range lang.Extensions {
+		r.byExtension[strings.TrimPrefix(ext, ".")] = lang
+	}
+}
+
+// ForExtension looks up a language by file extension (with or without
+// the leading dot)
+func (r *Registry) ForExtension(ext string) *Language {
+	if r == nil {
+		return nil
+	}
+	return r.byExtension[strings.TrimPrefix(ext, ".")]
+}
+
+// ForFile looks up a language by filename, using its extension
+func (r *Registry) ForFile(filename string) *Language {
+	return r.ForExtension(filepath.Ext(filename))
+}
@@ -0,0 +1,98 @@
+/*
+The find bar is the incremental search/replace strip that slides in below
+the toolbar when the user triggers the "search" action, following the
+pattern of Leksah's findbar and Textadept's find pane: a single entry plus
+Prev/Next/Replace buttons and toggle buttons for Case/Whole-Word/Regex/
+Incremental. MainUI owns exactly one FindBar and shows/hides it as a
+canvas window like every other bar.
+*/
+
+package ui
+
+import "fmt"
+
+// FindBarWindowTitle is the canvas window title used for the find bar so it
+// can be located/removed the same way every other MainUI bar is.
+const FindBarWindowTitle = "Find Bar"
+
+// FindBar is the incremental find/replace entry shown below the toolbar
+//
+type FindBar struct {
+	Visible     bool
+	Query       string
+	ReplaceWith string
+
+	CaseSensitive bool
+	WholeWord     bool
+	Regex         bool
+	Incremental   bool
+}
+
+// ShowFindBar reveals the find bar, adding it as a window on the canvas
+func (ui *MainUI) ShowFindBar() {
+	if ui.FindBar.Visible {
+		return
+	}
+	ui.FindBar.Visible = true
+
+	ui.Canvas.AddWindow(Window{
+		Title:      FindBarWindowTitle,
+		X:          0,
+		Y:          6,
+		Width:      ui.Canvas.Width,
+		Height:     3,
+		Content:    ui.renderFindBar(),
+		Visible:    true,
+		Resizable:  false,
+		WindowType: "findbar",
+	})
+}
+
+// HideFindBar hides the find bar and clears its query
+func (ui *MainUI) HideFindBar() {
+	if !ui.FindBar.Visible {
+		return
+	}
+	ui.FindBar.Visible = false
+	ui.FindBar.Query = ""
+	ui.Canvas.RemoveWindow(FindBarWindowTitle)
+}
+
+// ToggleFindBar shows the find bar if hidden, hides it if shown
+func (ui *MainUI) ToggleFindBar() {
+	if ui.FindBar.Visible {
+		ui.HideFindBar()
+	} else {
+		ui.ShowFindBar()
+	}
+}
+
+// UpdateFindQuery sets the current query text and refreshes the find bar
+// window content. Callers that want incremental search should run the
+// search themselves (against SearchManager) after calling this and before
+// re-rendering, since FindBar has no reference to the search logic.
+func (ui *MainUI) UpdateFindQuery(query string) {
+	ui.FindBar.Query = query
+	if ui.FindBar.Visible {
+		ui.Canvas.UpdateWindowContent(FindBarWindowTitle, ui.renderFindBar())
+	}
+}
+
+// renderFindBar builds the textual content of the find bar window
+func (ui *MainUI) renderFindBar() string {
+	flags := ""
+	if ui.FindBar.CaseSensitive {
+		flags += "[Case] "
+	}
+	if ui.FindBar.WholeWord {
+		flags += "[Word] "
+	}
+	if ui.FindBar.Regex {
+		flags += "[Regex] "
+	}
+	if ui.FindBar.Incremental {
+		flags += "[Incremental] "
+	}
+
+	return fmt.Sprintf("Find: %s_  %s(Enter: next, Shift+Enter: prev, Esc: close)", ui.FindBar.Query, flags)
+}
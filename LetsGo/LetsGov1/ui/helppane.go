@@ -0,0 +1,77 @@
+/*
+HelpPane renders the searchable keybinding help overlay bound to F1: a
+scrollable table of every registered action/key pair that narrows as the
+user types in a search box above it. MainUI only owns the window and the
+rows to display; the fuzzy filtering itself lives in keybinds.HelpPane so
+ui doesn't need to know how matching works, only what to draw.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"letsgo-editor/keybinds"
+)
+
+// HelpPaneWindowTitle is the canvas window title used for the help pane
+const HelpPaneWindowTitle = "Keybindings"
+
+// HelpPaneState tracks whether the help pane is on screen and its current
+// search box contents
+type HelpPaneState struct {
+	Visible bool
+	Query   string
+}
+
+// ShowHelpPane reveals the keybinding help overlay with the given rows
+func (ui *MainUI) ShowHelpPane(rows []keybinds.Keybinding) {
+	ui.HelpPane.Visible = true
+
+	ui.Canvas.AddWindow(Window{
+		Title:      HelpPaneWindowTitle,
+		X:          ui.Canvas.Width / 4,
+		Y:          ui.Canvas.Height / 4,
+		Width:      ui.Canvas.Width / 2,
+		Height:     ui.Canvas.Height / 2,
+		Content:    renderHelpPaneRows(ui.HelpPane.Query, rows),
+		Visible:    true,
+		Resizable:  true,
+		WindowType: "help",
+	})
+}
+
+// HideHelpPane dismisses the help overlay
+func (ui *MainUI) HideHelpPane() {
+	ui.HelpPane.Visible = false
+	ui.HelpPane.Query = ""
+	ui.Canvas.RemoveWindow(HelpPaneWindowTitle)
+}
+
+// UpdateHelpPane sets the search query and redraws the overlay with the
+// already-filtered rows the caller computed via keybinds.HelpPane.Filter.
+func (ui *MainUI) UpdateHelpPane(query string, rows []keybinds.Keybinding) {
+	ui.HelpPane.Query = query
+	if ui.HelpPane.Visible {
+		ui.Canvas.UpdateWindowContent(HelpPaneWindowTitle, renderHelpPaneRows(query, rows))
+	}
+}
+
+// renderHelpPaneRows formats the filtered bindings as a two-column table
+func renderHelpPaneRows(query string, rows []keybinds.Keybinding) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Search: %s_\n", query))
+	content.WriteString(strings.Repeat("-", 30) + "\n")
+
+	if len(rows) == 0 {
+		content.WriteString("(no matching keybindings)")
+		return content.String()
+	}
+
+	for _, row := range rows {
+		content.WriteString(fmt.Sprintf("%-24s %s\n", row.Action, row.Key))
+	}
+
+	return content.String()
+}
@@ -0,0 +1,93 @@
+/*
+The hint bar is a toggleable strip shown above the StatusBar while a chord
+is pending: it lists every key that continues the chord, labelled with
+whatever the action is called in the MenuBar/EditBar/Toolbar, so pressing
+"Ctrl+G" can show "s save | o open | w close" instead of leaving the user
+guessing. It mirrors FindBar's own show/hide-as-a-canvas-window pattern.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"letsgo-editor/keybinds"
+)
+
+// HintBarWindowTitle is the canvas window title used for the hint bar
+const HintBarWindowTitle = "Key Hints"
+
+// HintBar shows the reachable next keys of an in-progress chord
+type HintBar struct {
+	Visible bool
+}
+
+// ShowHintBar reveals the hint bar, adding it as a window directly above
+// the status bar, rendering completions (see keybinds.KeyResolver.
+// Completions) as "key label" pairs.
+func (ui *MainUI) ShowHintBar(completions []keybinds.Completion) {
+	if !ui.HintBar.Visible {
+		ui.HintBar.Visible = true
+		ui.Canvas.AddWindow(Window{
+			Title:      HintBarWindowTitle,
+			X:          0,
+			Y:          ui.Canvas.Height - 6,
+			Width:      ui.Canvas.Width,
+			Height:     3,
+			Content:    ui.renderHintBar(completions),
+			Visible:    true,
+			Resizable:  false,
+			WindowType: "hintbar",
+		})
+		return
+	}
+	ui.Canvas.UpdateWindowContent(HintBarWindowTitle, ui.renderHintBar(completions))
+}
+
+// HideHintBar hides the hint bar
+func (ui *MainUI) HideHintBar() {
+	if !ui.HintBar.Visible {
+		return
+	}
+	ui.HintBar.Visible = false
+	ui.Canvas.RemoveWindow(HintBarWindowTitle)
+}
+
+// renderHintBar builds the "key label | key label" content of the hint
+// bar from the resolver's current completions, falling back to the raw
+// action name (or "..." for a completion that continues an even longer
+// chord) when no menu/edit/toolbar item names it.
+func (ui *MainUI) renderHintBar(completions []keybinds.Completion) string {
+	parts := make([]string, 0, len(completions))
+	for _, c := range completions {
+		label := "..."
+		if c.Action != "" {
+			label = ui.labelForAction(c.Action)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", c.Key, label))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// labelForAction looks up how an action is labelled in the MenuBar,
+// EditBar, or Toolbar (in that order), falling back to the action string
+// itself when none of them mention it.
+func (ui *MainUI) labelForAction(action string) string {
+	for _, item := range ui.MenuBar.Items {
+		if item.Action == action {
+			return strings.ToLower(item.Label)
+		}
+	}
+	for _, item := range ui.EditBar.Items {
+		if item.Action == action {
+			return strings.ToLower(item.Label)
+		}
+	}
+	for _, button := range ui.Toolbar.Buttons {
+		if button.Action == action {
+			return strings.ToLower(button.Label)
+		}
+	}
+	return action
+}
@@ -16,6 +16,8 @@ package ui
 import (
 	"fmt"
 	"strings"
+
+	"letsgo-editor/syntax"
 )
 
 // MainUI is the main UI controller for the editor
@@ -28,21 +30,55 @@ type MainUI struct {
 	Toolbar   Toolbar
 	StatusBar StatusBar
 	TextArea  TextArea
+	FindBar   FindBar
+	HelpPane  HelpPaneState
+	Palette   Palette
+	HintBar   HintBar
+	Panes     *PaneManager
+	Settings  *SettingsWindow
+	Supervisor Supervisor
+	Splits    []SplitPane
 	Initialized bool
 }
 
+// SplitPane is one leaf pane's resolved display data: plain geometry and
+// text, with no dependency on logic.BuffersManager's split tree (ui never
+// imports logic). EditorState.syncTextArea fills this in every frame by
+// walking the real tree and resolving each pane's active buffer, the same
+// way it already fills in TextArea.Content from EditManager.Doc. An empty
+// Splits means no split has been made yet, so the editor stays the
+// single plain "Editor" window it always was.
+type SplitPane struct {
+	Title  string // Canvas window title: "Editor" for the primary pane, "Editor#<id>" for the rest
+	X, Y   int
+	Width  int
+	Height int
+
+	Content      string
+	CursorLine   int
+	CursorColumn int
+	ScrollOffset int
+	Highlights   [][]syntax.Span
+	Focused      bool
+}
+
 // MenuBar represents the file operations menu
 //
 type MenuBar struct {
-	Items []MenuItem
+	Items   []MenuItem
+	Buttons []MenuButton // cascading view of Items shown in the bar and opened as popups; see Supervisor
 	Visible bool
 }
 
-// MenuItem represents a menu item
+// MenuItem represents a row in a menu or popup: either a leaf bound to an
+// Action, a Separator (a divider with no Action), or a row that opens a
+// nested Submenu instead of dispatching anything itself.
 type MenuItem struct {
-	Label  string
-	Action string
-	Key    string // Keyboard shortcut
+	Label     string
+	Action    string
+	Key       string // Keyboard shortcut
+	Separator bool
+	Submenu   []MenuItem
 }
 
 // EditBar represents the edit operations menu
@@ -65,17 +101,22 @@ type ToolbarButton struct {
 	Label  string
 	Action string
 	Icon   string // Simple text icon
+	Menu   []MenuItem // optional dropdown opened instead of Action when clicked
+
+	X, Width int // hit-test region within the toolbar, set each render
 }
 
 // StatusBar shows file name, cursor position, etc.
 //
 type StatusBar struct {
-	FileName     string
-	CursorLine   int
-	CursorColumn int
-	FileSize     int
-	Modified     bool
-	Visible      bool
+	FileName      string
+	CursorLine    int
+	CursorColumn  int
+	FileSize      int
+	Modified      bool
+	Visible       bool
+	PendingPrefix string // keys fed so far for an in-progress chord, e.g. "Ctrl+X"
+	LineEnding    string // detected/selected line ending of the current buffer, e.g. "LF"
 }
 
 // TextArea is the main text editing area, with numberline integrated
@@ -107,7 +148,10 @@ func (ui *MainUI) Initialize() {
 		},
 		Visible: true,
 	}
-	
+	ui.MenuBar.Buttons = []MenuButton{
+		{Label: "File", Items: append([]MenuItem{}, ui.MenuBar.Items...)},
+	}
+
 	// Initialize EditBar
 	ui.EditBar = EditBar{
 		Items: []MenuItem{
@@ -162,87 +206,41 @@ func (ui *MainUI) Initialize() {
 	fmt.Println("Main UI initialized with modern, clean design")
 }
 
-// addUIWindows adds all UI components as windows to the canvas
+// addUIWindows adds all UI components as windows to the canvas, then hands
+// them to a PaneManager that computes their actual X/Y/Width/Height from
+// each pane's dock - nobody here hard-codes the Y: 0/3/6/9 offsets the
+// layout used to have; Panes.Update() derives them and keeps them correct
+// across toggles and terminal resizes (see Resize).
 func (ui *MainUI) addUIWindows() {
-	// Add menu bar window
 	if ui.MenuBar.Visible {
-		menuContent := ui.renderMenuBar()
-		ui.Canvas.AddWindow(Window{
-			Title:      "Menu Bar",
-			X:          0,
-			Y:          0,
-			Width:      ui.Canvas.Width,
-			Height:     3,
-			Content:    menuContent,
-			Visible:    true,
-			Resizable:  false,
-			WindowType: "menu",
-		})
+		ui.Canvas.AddWindow(Window{Title: "Menu Bar", Content: ui.renderMenuBar(), Resizable: false, WindowType: "menu"})
 	}
-	
-	// Add edit bar window (buffers menu as requested)
+
+	// Edit bar window (buffers menu as requested)
 	if ui.EditBar.Visible {
-		editContent := ui.renderEditBar()
-		ui.Canvas.AddWindow(Window{
-			Title:      "Buffers",
-			X:          0,
-			Y:          3,
-			Width:      ui.Canvas.Width,
-			Height:     3,
-			Content:    editContent,
-			Visible:    true,
-			Resizable:  false,
-			WindowType: "menu",
-		})
+		ui.Canvas.AddWindow(Window{Title: "Buffers", Content: ui.renderEditBar(), Resizable: false, WindowType: "menu"})
 	}
-	
-	// Add toolbar window
+
 	if ui.Toolbar.Visible {
-		toolbarContent := ui.renderToolbar()
-		ui.Canvas.AddWindow(Window{
-			Title:      "Toolbar",
-			X:          0,
-			Y:          6,
-			Width:      ui.Canvas.Width,
-			Height:     3,
-			Content:    toolbarContent,
-			Visible:    true,
-			Resizable:  false,
-			WindowType: "toolbar",
-		})
+		ui.Canvas.AddWindow(Window{Title: "Toolbar", Content: ui.renderToolbar(), Resizable: false, WindowType: "toolbar"})
 	}
-	
-	// Add text area window (main editor)
+
+	// Main editor window
 	if ui.TextArea.Visible {
-		textContent := ui.renderTextArea()
-		ui.Canvas.AddWindow(Window{
-			Title:      "Editor",
-			X:          0,
-			Y:          9,
-			Width:      ui.Canvas.Width,
-			Height:     ui.Canvas.Height - 12, // Leave space for status bar
-			Content:    textContent,
-			Visible:    true,
-			Resizable:  true,
-			WindowType: "editor",
-		})
+		ui.Canvas.AddWindow(Window{Title: "Editor", Content: ui.renderTextArea(), Resizable: true, WindowType: "editor"})
 	}
-	
-	// Add status bar window
+
 	if ui.StatusBar.Visible {
-		statusContent := ui.renderStatusBar()
-		ui.Canvas.AddWindow(Window{
-			Title:      "Status Bar",
-			X:          0,
-			Y:          ui.Canvas.Height - 3,
-			Width:      ui.Canvas.Width,
-			Height:     3,
-			Content:    statusContent,
-			Visible:    true,
-			Resizable:  false,
-			WindowType: "status",
-		})
+		ui.Canvas.AddWindow(Window{Title: "Status Bar", Content: ui.renderStatusBar(), Resizable: false, WindowType: "status"})
 	}
+
+	ui.Panes = NewPaneManager(ui.Canvas)
+	ui.Panes.AddPane(PaneInfo{Title: "Menu Bar", Dock: DockTop, Order: 0, MinSize: 3, Size: 3, Visible: ui.MenuBar.Visible})
+	ui.Panes.AddPane(PaneInfo{Title: "Buffers", Dock: DockTop, Order: 1, MinSize: 3, Size: 3, Visible: ui.EditBar.Visible})
+	ui.Panes.AddPane(PaneInfo{Title: "Toolbar", Dock: DockTop, Order: 2, MinSize: 3, Size: 3, Closable: true, Visible: ui.Toolbar.Visible})
+	ui.Panes.AddPane(PaneInfo{Title: "Status Bar", Dock: DockBottom, Order: 0, MinSize: 3, Size: 3, Visible: ui.StatusBar.Visible})
+	ui.Panes.AddPane(PaneInfo{Title: "Editor", Dock: DockCenter, Visible: ui.TextArea.Visible})
+	ui.Panes.Update()
 }
 
 // renderMenuBar creates the menu bar content
@@ -275,18 +273,27 @@ func (ui *MainUI) renderEditBar() string {
 	return content.String()
 }
 
-// renderToolbar creates the toolbar content
+// renderToolbar creates the toolbar content, recording each button's
+// column range in Toolbar.Buttons[i].X/Width as it goes so mouse clicks
+// can be hit-tested against it later (see MainUI.toolbarButtonAt).
 func (ui *MainUI) renderToolbar() string {
 	var content strings.Builder
 	content.WriteString("Tools: ")
-	
-	for i, button := range ui.Toolbar.Buttons {
+	x := content.Len()
+
+	for i := range ui.Toolbar.Buttons {
+		button := &ui.Toolbar.Buttons[i]
 		if i > 0 {
 			content.WriteString(" ")
+			x++
 		}
-		content.WriteString(fmt.Sprintf("%s%s", button.Icon, button.Label))
+		label := fmt.Sprintf("%s%s", button.Icon, button.Label)
+		button.X = x
+		button.Width = len(label)
+		content.WriteString(label)
+		x += len(label)
 	}
-	
+
 	if ui.Toolbar.Customizable {
 		content.WriteString(" (Customizable)")
 	}
@@ -294,32 +301,43 @@ func (ui *MainUI) renderToolbar() string {
 	return content.String()
 }
 
-// renderTextArea creates the text area content with line numbers
+// renderTextArea creates the text area content with line numbers. With no
+// split layout in effect (Splits empty, the state every editor session
+// starts in) this is just the single TextArea the editor always had; see
+// updateEditorWindows for how additional panes are rendered once
+// EditorState.syncTextArea starts filling Splits in from a
+// logic.BuffersManager split tree (ui deliberately never imports logic).
 func (ui *MainUI) renderTextArea() string {
+	return formatPaneContent(ui.TextArea.Content, ui.TextArea.ShowNumbers,
+		ui.TextArea.ScrollOffset, ui.TextArea.CursorLine, ui.TextArea.CursorColumn)
+}
+
+// formatPaneContent renders one pane's text with line numbers and a
+// trailing cursor indicator - the shared formatter behind renderTextArea
+// and every split pane's window content.
+func formatPaneContent(text string, showNumbers bool, scrollOffset, cursorLine, cursorColumn int) string {
 	var content strings.Builder
-	
-	if ui.TextArea.Content == "" {
-		if ui.TextArea.ShowNumbers {
+
+	if text == "" {
+		if showNumbers {
 			content.WriteString("1 | (empty file)")
 		} else {
 			content.WriteString("(empty file)")
 		}
 	} else {
-		lines := strings.Split(ui.TextArea.Content, "\n")
+		lines := strings.Split(text, "\n")
 		for i, line := range lines {
-			lineNum := i + 1 + ui.TextArea.ScrollOffset
-			if ui.TextArea.ShowNumbers {
+			lineNum := i + 1 + scrollOffset
+			if showNumbers {
 				content.WriteString(fmt.Sprintf("%3d | %s\n", lineNum, line))
 			} else {
 				content.WriteString(line + "\n")
 			}
 		}
 	}
-	
-	// Add cursor position indicator
-	content.WriteString(fmt.Sprintf("\nCursor: Line %d, Column %d", 
-		ui.TextArea.CursorLine, ui.TextArea.CursorColumn))
-	
+
+	content.WriteString(fmt.Sprintf("\nCursor: Line %d, Column %d", cursorLine, cursorColumn))
+
 	return content.String()
 }
 
@@ -330,12 +348,24 @@ func (ui *MainUI) renderStatusBar() string {
 		modifiedIndicator = "*"
 	}
 	
-	return fmt.Sprintf("File: %s%s | Line: %d, Col: %d | Size: %d bytes", 
-		ui.StatusBar.FileName, 
+	pendingIndicator := ""
+	if ui.StatusBar.PendingPrefix != "" {
+		pendingIndicator = fmt.Sprintf(" | %s...", ui.StatusBar.PendingPrefix)
+	}
+
+	lineEndingIndicator := ""
+	if ui.StatusBar.LineEnding != "" {
+		lineEndingIndicator = fmt.Sprintf(" | %s", ui.StatusBar.LineEnding)
+	}
+
+	return fmt.Sprintf("File: %s%s | Line: %d, Col: %d | Size: %d bytes%s%s",
+		ui.StatusBar.FileName,
 		modifiedIndicator,
-		ui.StatusBar.CursorLine, 
+		ui.StatusBar.CursorLine,
 		ui.StatusBar.CursorColumn,
-		ui.StatusBar.FileSize)
+		ui.StatusBar.FileSize,
+		lineEndingIndicator,
+		pendingIndicator)
 }
 
 // Render draws the entire UI using the canvas
@@ -356,8 +386,50 @@ func (ui *MainUI) updateWindowContents() {
 	ui.Canvas.UpdateWindowContent("Menu Bar", ui.renderMenuBar())
 	ui.Canvas.UpdateWindowContent("Buffers", ui.renderEditBar())
 	ui.Canvas.UpdateWindowContent("Toolbar", ui.renderToolbar())
-	ui.Canvas.UpdateWindowContent("Editor", ui.renderTextArea())
+	ui.updateEditorWindows()
 	ui.Canvas.UpdateWindowContent("Status Bar", ui.renderStatusBar())
+	if ui.FindBar.Visible {
+		ui.Canvas.UpdateWindowContent(FindBarWindowTitle, ui.renderFindBar())
+	}
+	if ui.Palette.Visible {
+		ui.Canvas.UpdateWindowContent(PaletteWindowTitle, ui.renderPalette())
+		ui.Canvas.UpdateWindowHighlights(PaletteWindowTitle, ui.paletteHighlights())
+	}
+	ui.RefreshSettingsWindow()
+}
+
+// updateEditorWindows refreshes the editor area's window(s): just the
+// single "Editor" window when Splits is empty (the editor's default
+// state), or one window per Splits entry when a split layout is active,
+// adding/repositioning each one and removing any leftover pane window a
+// previous, larger layout left behind.
+func (ui *MainUI) updateEditorWindows() {
+	if len(ui.Splits) == 0 {
+		ui.Canvas.UpdateWindowContent("Editor", ui.renderTextArea())
+		return
+	}
+
+	keep := make(map[string]bool, len(ui.Splits))
+	for _, p := range ui.Splits {
+		keep[p.Title] = true
+
+		if ui.Canvas.FindWindow(p.Title) == nil {
+			ui.Canvas.AddWindow(Window{Title: p.Title, Resizable: true, WindowType: "editor"})
+		}
+		ui.Canvas.SetWindowRect(p.Title, p.X, p.Y, p.Width, p.Height)
+		ui.Canvas.UpdateWindowContent(p.Title, formatPaneContent(p.Content, ui.TextArea.ShowNumbers, p.ScrollOffset, p.CursorLine, p.CursorColumn))
+		ui.Canvas.UpdateWindowHighlights(p.Title, p.Highlights)
+	}
+
+	var stale []string
+	for _, w := range ui.Canvas.Windows {
+		if w.WindowType == "editor" && !keep[w.Title] {
+			stale = append(stale, w.Title)
+		}
+	}
+	for _, title := range stale {
+		ui.Canvas.RemoveWindow(title)
+	}
 }
 
 // UpdateTextContent updates the text area content
@@ -393,10 +465,13 @@ func (ui *MainUI) ToggleLineNumbers() {
 	fmt.Printf("Line numbers: %t\n", ui.TextArea.ShowNumbers)
 }
 
-// ToggleToolbar toggles toolbar visibility
+// ToggleToolbar toggles toolbar visibility, reflowing the other panes
+// into (or back out of) the space it frees up.
 func (ui *MainUI) ToggleToolbar() {
 	ui.Toolbar.Visible = !ui.Toolbar.Visible
-	ui.Canvas.ToggleWindowVisibility("Toolbar")
+	if ui.Panes != nil {
+		ui.Panes.SetVisible("Toolbar", ui.Toolbar.Visible)
+	}
 	fmt.Printf("Toolbar visibility: %t\n", ui.Toolbar.Visible)
 }
 
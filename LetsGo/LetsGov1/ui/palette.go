@@ -0,0 +1,154 @@
+/*
+Palette is the fzf-style picker bound to Ctrl+P (file finder) and
+Ctrl+Shift+P (command palette): a scrolling, best-match-first result list
+with a prompt line below it. MainUI only owns the window and the already-
+scored rows to display; the fuzzy matching itself lives in search/fuzzy so
+ui doesn't need to know how scoring works, only what to draw - the same
+split HelpPane uses for keybinds.HelpPane.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"letsgo-editor/syntax"
+)
+
+// PaletteWindowTitle is the canvas window title used for the palette
+const PaletteWindowTitle = "Palette"
+
+// PaletteMaxRows bounds how many results are shown above the prompt line
+const PaletteMaxRows = 12
+
+// PaletteItem is a single scored candidate: Positions are the matched
+// rune indices into Label, used to accent-color the match in the list.
+type PaletteItem struct {
+	Label     string
+	Positions []int
+}
+
+// Palette is the fzf-style picker state: Mode distinguishes what
+// selecting an item does ("file" opens it, "command" runs it), candidates
+// arrive pre-scored/sorted via SetResults, and Selected indexes the
+// currently highlighted row.
+type Palette struct {
+	Visible  bool
+	Mode     string // "file" or "command"
+	Query    string
+	Items    []PaletteItem
+	Selected int
+}
+
+// ShowPalette reveals the palette for the given mode, empty until the
+// caller streams in results via SetResults
+func (ui *MainUI) ShowPalette(mode string) {
+	if ui.Palette.Visible {
+		return
+	}
+	ui.Palette = Palette{Visible: true, Mode: mode}
+
+	ui.Canvas.AddWindow(Window{
+		Title:      PaletteWindowTitle,
+		X:          ui.Canvas.Width / 6,
+		Y:          ui.Canvas.Height / 5,
+		Width:      ui.Canvas.Width * 2 / 3,
+		Height:     PaletteMaxRows + 3,
+		Content:    ui.renderPalette(),
+		Visible:    true,
+		Resizable:  false,
+		WindowType: "palette",
+	})
+}
+
+// HidePalette dismisses the palette and clears its state
+func (ui *MainUI) HidePalette() {
+	if !ui.Palette.Visible {
+		return
+	}
+	ui.Palette = Palette{}
+	ui.Canvas.RemoveWindow(PaletteWindowTitle)
+}
+
+// SetPaletteResults sets the current query and its matching items (already
+// scored/sorted by the caller via search/fuzzy.Score) and refreshes the
+// window content/highlights
+func (ui *MainUI) SetPaletteResults(query string, items []PaletteItem) {
+	ui.Palette.Query = query
+	ui.Palette.Items = items
+	if ui.Palette.Selected >= len(items) {
+		ui.Palette.Selected = 0
+	}
+	if !ui.Palette.Visible {
+		return
+	}
+	ui.Canvas.UpdateWindowContent(PaletteWindowTitle, ui.renderPalette())
+	ui.Canvas.UpdateWindowHighlights(PaletteWindowTitle, ui.paletteHighlights())
+}
+
+// MovePaletteSelection moves the highlighted row by delta, wrapping at
+// either end of the result list
+func (ui *MainUI) MovePaletteSelection(delta int) {
+	n := len(ui.Palette.Items)
+	if n == 0 {
+		return
+	}
+	ui.Palette.Selected = ((ui.Palette.Selected+delta)%n + n) % n
+}
+
+// SelectedPaletteItem returns the currently highlighted item, or false if
+// the result list is empty
+func (ui *MainUI) SelectedPaletteItem() (PaletteItem, bool) {
+	if ui.Palette.Selected < 0 || ui.Palette.Selected >= len(ui.Palette.Items) {
+		return PaletteItem{}, false
+	}
+	return ui.Palette.Items[ui.Palette.Selected], true
+}
+
+// renderPalette builds the textual content of the palette window: the
+// result list (capped at PaletteMaxRows, highlighted row marked with
+// "> "), then a blank separator line and the prompt.
+func (ui *MainUI) renderPalette() string {
+	var content strings.Builder
+
+	for i, item := range ui.Palette.Items {
+		if i >= PaletteMaxRows {
+			break
+		}
+		marker := "  "
+		if i == ui.Palette.Selected {
+			marker = "> "
+		}
+		content.WriteString(marker + item.Label + "\n")
+	}
+	if len(ui.Palette.Items) == 0 {
+		content.WriteString("(no matches)\n")
+	}
+
+	content.WriteString(fmt.Sprintf("\n> %s_  (Enter: select, Esc: close)", ui.Palette.Query))
+	return content.String()
+}
+
+// paletteHighlights builds the per-row accent spans for the matched
+// positions of each displayed result, shifted by the "  "/"> " marker
+// prefix renderPalette writes in front of every label.
+func (ui *MainUI) paletteHighlights() [][]syntax.Span {
+	const markerLen = 2
+
+	rows := len(ui.Palette.Items)
+	if rows > PaletteMaxRows {
+		rows = PaletteMaxRows
+	}
+
+	highlights := make([][]syntax.Span, rows)
+	for i := 0; i < rows; i++ {
+		item := ui.Palette.Items[i]
+		spans := make([]syntax.Span, len(item.Positions))
+		for j, pos := range item.Positions {
+			spans[j] = syntax.Span{Start: pos + markerLen, End: pos + markerLen + 1, Color: "accent"}
+		}
+		highlights[i] = spans
+	}
+	return highlights
+}
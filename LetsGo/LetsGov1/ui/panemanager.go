@@ -0,0 +1,266 @@
+/*
+PaneManager replaces the hard-coded X/Y/Width/Height offsets MainUI used
+to compute by hand with a real dock layout, the way wxWidgets' AUI manager
+or Eclipse's workbench lay out docked views: MenuBar, EditBar, Toolbar,
+StatusBar, and TextArea each become a pane docked to an edge (or Center),
+and Update() walks the dock order to recompute every pane's rect, so
+toggling a bar or resizing the terminal reflows the rest automatically
+instead of anyone re-deriving Y: 0/3/6/9 offsets. This editor has no mouse
+handling to drag a splitter with, so "dragging" a split is done by
+ResizePane nudging a pane's Size the way a keybinding would.
+*/
+
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Dock is which edge of the canvas a pane is anchored to. DockCenter panes
+// fill whatever space the docked edges leave behind.
+type Dock int
+
+const (
+	DockTop Dock = iota
+	DockBottom
+	DockLeft
+	DockRight
+	DockCenter
+)
+
+// PaneInfo describes one pane's place in the dock layout. Title must match
+// the pane's Canvas Window title so PaneManager can find it to reposition.
+type PaneInfo struct {
+	Title     string `json:"title"`
+	Dock      Dock   `json:"dock"`
+	Order     int    `json:"order"`   // lower Order docks closer to the edge first
+	MinSize   int    `json:"min_size"` // minimum height (Top/Bottom) or width (Left/Right)
+	Size      int    `json:"size"`     // height/width actually reserved, floored at MinSize
+	Floatable bool   `json:"floatable"`
+	Closable  bool   `json:"closable"`
+	Visible   bool   `json:"visible"`
+
+	// TabGroup, when non-empty, groups this pane with every other pane
+	// sharing the same TabGroup+Dock into one tabbed slot: only the
+	// group's ActiveTab occupies the rect the group would otherwise take,
+	// the rest sit hidden until SetActiveTab switches them in.
+	TabGroup string `json:"tab_group,omitempty"`
+}
+
+// PaneManager owns the Canvas and the dock tree describing where every
+// registered pane sits.
+type PaneManager struct {
+	Canvas *Canvas
+	Panes  []PaneInfo
+}
+
+// NewPaneManager creates a PaneManager laying out windows on canvas
+func NewPaneManager(canvas *Canvas) *PaneManager {
+	return &PaneManager{Canvas: canvas}
+}
+
+// AddPane registers a pane's dock info, replacing any existing entry with
+// the same Title.
+func (pm *PaneManager) AddPane(info PaneInfo) {
+	for i := range pm.Panes {
+		if pm.Panes[i].Title == info.Title {
+			pm.Panes[i] = info
+			return
+		}
+	}
+	pm.Panes = append(pm.Panes, info)
+}
+
+// Pane looks up a registered pane's info by title
+func (pm *PaneManager) Pane(title string) *PaneInfo {
+	for i := range pm.Panes {
+		if pm.Panes[i].Title == title {
+			return &pm.Panes[i]
+		}
+	}
+	return nil
+}
+
+// SetVisible shows or hides a pane (a no-op if it isn't Closable and the
+// caller is trying to hide it) and reflows the layout.
+func (pm *PaneManager) SetVisible(title string, visible bool) {
+	p := pm.Pane(title)
+	if p == nil || (!visible && !p.Closable) {
+		return
+	}
+	p.Visible = visible
+	pm.Update()
+}
+
+// ResizePane nudges a docked pane's Size by delta rows/columns (clamped at
+// MinSize) and reflows - the keyboard stand-in for dragging a splitter.
+func (pm *PaneManager) ResizePane(title string, delta int) {
+	p := pm.Pane(title)
+	if p == nil {
+		return
+	}
+	p.Size += delta
+	if p.Size < p.MinSize {
+		p.Size = p.MinSize
+	}
+	pm.Update()
+}
+
+// SetActiveTab makes title the visible pane of its TabGroup, hiding every
+// other pane sharing that group, and reflows.
+func (pm *PaneManager) SetActiveTab(group, title string) {
+	for i := range pm.Panes {
+		if pm.Panes[i].TabGroup == group {
+			pm.Panes[i].Visible = pm.Panes[i].Title == title
+		}
+	}
+	pm.Update()
+}
+
+// docked returns the visible, non-tab-hidden panes on dock, in Order
+func (pm *PaneManager) docked(dock Dock) []*PaneInfo {
+	var out []*PaneInfo
+	for i := range pm.Panes {
+		if pm.Panes[i].Dock == dock && pm.Panes[i].Visible {
+			out = append(out, &pm.Panes[i])
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Order < out[j].Order })
+	return out
+}
+
+// Update recomputes every visible pane's rect from the dock tree and
+// applies it to the matching Canvas window: Top panes stack downward from
+// y=0 in Order, Bottom panes stack upward from the bottom, Left/Right
+// panes stack inward from their edge within whatever vertical band Top/
+// Bottom left behind, and Center panes fill what remains. Hidden panes'
+// windows are hidden on the Canvas rather than resized to nothing.
+func (pm *PaneManager) Update() {
+	if pm.Canvas == nil {
+		return
+	}
+
+	top, bottom, left, right := 0, 0, 0, 0
+
+	for _, p := range pm.docked(DockTop) {
+		size := sizeOf(p)
+		pm.apply(p, 0, top, pm.Canvas.Width, size)
+		top += size
+	}
+	for _, p := range pm.docked(DockBottom) {
+		size := sizeOf(p)
+		bottom += size
+		pm.apply(p, 0, pm.Canvas.Height-bottom, pm.Canvas.Width, size)
+	}
+
+	midHeight := pm.Canvas.Height - top - bottom
+	for _, p := range pm.docked(DockLeft) {
+		size := sizeOf(p)
+		pm.apply(p, left, top, size, midHeight)
+		left += size
+	}
+	for _, p := range pm.docked(DockRight) {
+		size := sizeOf(p)
+		right += size
+		pm.apply(p, pm.Canvas.Width-right, top, size, midHeight)
+	}
+
+	centerWidth := pm.Canvas.Width - left - right
+	for _, p := range pm.docked(DockCenter) {
+		pm.apply(p, left, top, centerWidth, midHeight)
+	}
+
+	for i := range pm.Panes {
+		if !pm.Panes[i].Visible {
+			pm.Canvas.SetWindowVisible(pm.Panes[i].Title, false)
+		}
+	}
+}
+
+// sizeOf returns a pane's reserved height/width, floored at MinSize
+func sizeOf(p *PaneInfo) int {
+	if p.Size < p.MinSize {
+		return p.MinSize
+	}
+	return p.Size
+}
+
+// apply pushes a computed rect onto the pane's Canvas window
+func (pm *PaneManager) apply(p *PaneInfo, x, y, width, height int) {
+	pm.Canvas.SetWindowRect(p.Title, x, y, width, height)
+	pm.Canvas.SetWindowVisible(p.Title, true)
+}
+
+// perspectiveDir returns the directory perspectives are stored in,
+// alongside the keybindings/history files under ~/.config/letsgo-editor.
+func perspectiveDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error resolving home directory: %v", err)
+	}
+	return filepath.Join(home, ".config", "letsgo-editor", "perspectives"), nil
+}
+
+// SavePerspective serializes the current pane layout - dock positions,
+// order, and size - under name, so LoadPerspective(name) can restore it.
+func (pm *PaneManager) SavePerspective(name string) error {
+	dir, err := perspectiveDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating perspectives directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(pm.Panes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling perspective: %v", err)
+	}
+
+	path := filepath.Join(dir, name+".json")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing perspective file: %v", err)
+	}
+
+	fmt.Printf("Saved perspective %q to %s\n", name, path)
+	return nil
+}
+
+// LoadPerspective loads the named perspective and reflows to match. A
+// pane not mentioned in the file keeps whatever info it's currently
+// registered with, so a perspective saved before a new pane existed
+// doesn't make that pane disappear.
+func (pm *PaneManager) LoadPerspective(name string) error {
+	dir, err := perspectiveDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, name+".json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("perspective %q does not exist", name)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading perspective file: %v", err)
+	}
+
+	var panes []PaneInfo
+	if err := json.Unmarshal(data, &panes); err != nil {
+		return fmt.Errorf("error parsing perspective file: %v", err)
+	}
+
+	for _, p := range panes {
+		pm.AddPane(p)
+	}
+	pm.Update()
+
+	fmt.Printf("Loaded perspective %q from %s\n", name, path)
+	return nil
+}
@@ -0,0 +1,382 @@
+/*
+SettingsWindow is a modal preferences dialog over Canvas, tabbed the way
+VS Code's or Sublime's settings UI is: General/Appearance/Controls/
+Toolbar, each a Frame of labeled controls bound by pointer straight to the
+live objects they configure (TextArea.ShowNumbers, Toolbar.Visible,
+ThemeManager.Current.*Color, ...) so a change takes effect immediately
+instead of waiting for an explicit "apply" step. The Controls tab is
+built differently: it lists every action MenuBar/EditBar/Toolbar know
+about and lets the user rebind or clear its key.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"letsgo-editor/keybinds"
+)
+
+// SettingsWindowTitle is the canvas window title used for the settings window
+const SettingsWindowTitle = "Settings"
+
+// ControlKind is what widget a Control renders as
+type ControlKind int
+
+const (
+	ControlCheckbox ControlKind = iota
+	ControlSpinner
+	ControlColorPicker
+	ControlCombobox
+)
+
+// Control is one labeled, live-bound setting shown in a SettingsWindow
+// tab. Exactly one of Bool/Int/Str is set, matching Kind, and is read and
+// written directly - there's no separate model copied back on save. If
+// OnApply is set it runs immediately after the bound value changes, for
+// settings that need more than just the pointer write (e.g. reflowing
+// panes when a bar's visibility changes).
+type Control struct {
+	Label   string
+	Kind    ControlKind
+	Bool    *bool
+	Int     *int
+	Str     *string
+	Options []string // cycled through by ControlCombobox / ControlColorPicker
+	OnApply func()
+}
+
+// Frame groups a tab's controls under its tab title
+type Frame struct {
+	Title    string
+	Controls []Control
+}
+
+// RebindEntry is one rebindable action shown in the Controls tab
+type RebindEntry struct {
+	Action string
+	Key    string
+}
+
+// SettingsWindow is the modal settings dialog. Build one with
+// NewSettingsWindow rather than constructing it directly, so the Controls
+// tab is populated from the current bindings.
+type SettingsWindow struct {
+	Visible   bool
+	Tabs      []string
+	ActiveTab int
+	Frames    map[string]Frame
+	Rebinds   []RebindEntry
+	Selected  int  // index into the active tab's rows
+	Recording bool // true while waiting for a captured key for Rebinds[Selected]
+
+	km *keybinds.KeybindManager
+}
+
+// NewSettingsWindow builds the General/Appearance/Controls/Toolbar tabs,
+// binding General and Toolbar's checkboxes to mainUI's live fields,
+// Appearance's color pickers to tm's live theme, and Controls to every
+// distinct action km knows a key for.
+func NewSettingsWindow(mainUI *MainUI, tm *ThemeManager, km *keybinds.KeybindManager) *SettingsWindow {
+	sw := &SettingsWindow{
+		Tabs: []string{"General", "Appearance", "Controls", "Toolbar"},
+		km:   km,
+	}
+
+	colorOptions := []string{"#1e1e1e", "#252526", "#2d2d30", "#007acc", "#d4d4d4", "#ffffff",
+		"#569cd6", "#ce9178", "#6a9955", "#b5cea8", "#858585"}
+
+	sw.Frames = map[string]Frame{
+		"General": {
+			Title: "General",
+			Controls: []Control{
+				{Label: "Show line numbers", Kind: ControlCheckbox, Bool: &mainUI.TextArea.ShowNumbers},
+				{Label: "Show status bar", Kind: ControlCheckbox, Bool: &mainUI.StatusBar.Visible,
+					OnApply: func() {
+						if mainUI.Panes != nil {
+							mainUI.Panes.SetVisible("Status Bar", mainUI.StatusBar.Visible)
+						}
+					}},
+			},
+		},
+		"Appearance": {
+			Title: "Appearance",
+			Controls: []Control{
+				{Label: "Background", Kind: ControlColorPicker, Str: &tm.Current.BackgroundColor, Options: colorOptions},
+				{Label: "Foreground", Kind: ControlColorPicker, Str: &tm.Current.ForegroundColor, Options: colorOptions},
+				{Label: "Accent", Kind: ControlColorPicker, Str: &tm.Current.AccentColor, Options: colorOptions},
+				{Label: "Menu bar", Kind: ControlColorPicker, Str: &tm.Current.MenuBarColor, Options: colorOptions},
+				{Label: "Status bar", Kind: ControlColorPicker, Str: &tm.Current.StatusBarColor, Options: colorOptions},
+			},
+		},
+		"Toolbar": {
+			Title: "Toolbar",
+			Controls: []Control{
+				{Label: "Show toolbar", Kind: ControlCheckbox, Bool: &mainUI.Toolbar.Visible,
+					OnApply: func() {
+						if mainUI.Panes != nil {
+							mainUI.Panes.SetVisible("Toolbar", mainUI.Toolbar.Visible)
+						}
+					}},
+				{Label: "Customizable", Kind: ControlCheckbox, Bool: &mainUI.Toolbar.Customizable},
+			},
+		},
+	}
+
+	sw.Rebinds = rebindEntries(mainUI, km)
+
+	return sw
+}
+
+// rebindEntries enumerates every distinct action named by MenuBar,
+// EditBar, or Toolbar, paired with its current key (blank if unbound).
+func rebindEntries(mainUI *MainUI, km *keybinds.KeybindManager) []RebindEntry {
+	seen := map[string]bool{}
+	var entries []RebindEntry
+
+	add := func(action string) {
+		if action == "" || seen[action] {
+			return
+		}
+		seen[action] = true
+		key := ""
+		if b := km.FindKeybinding(action); b != nil {
+			key = b.Key
+		}
+		entries = append(entries, RebindEntry{Action: action, Key: key})
+	}
+
+	for _, item := range mainUI.MenuBar.Items {
+		add(item.Action)
+	}
+	for _, item := range mainUI.EditBar.Items {
+		add(item.Action)
+	}
+	for _, button := range mainUI.Toolbar.Buttons {
+		add(button.Action)
+	}
+
+	return entries
+}
+
+// ShowSettingsWindow reveals the settings window, adding it as a
+// centered-ish window on the canvas (it's modal, so it floats above the
+// regular dock layout rather than taking a pane of its own).
+func (ui *MainUI) ShowSettingsWindow(tm *ThemeManager, km *keybinds.KeybindManager) {
+	if ui.Settings == nil {
+		ui.Settings = NewSettingsWindow(ui, tm, km)
+	}
+	if ui.Settings.Visible {
+		return
+	}
+	ui.Settings.Visible = true
+
+	width := ui.Canvas.Width - 10
+	if width < 20 {
+		width = ui.Canvas.Width
+	}
+	height := ui.Canvas.Height - 10
+	if height < 10 {
+		height = ui.Canvas.Height
+	}
+
+	ui.Canvas.AddWindow(Window{
+		Title:      SettingsWindowTitle,
+		X:          5,
+		Y:          5,
+		Width:      width,
+		Height:     height,
+		Content:    ui.Settings.Render(),
+		Visible:    true,
+		Resizable:  false,
+		WindowType: "settings",
+	})
+}
+
+// HideSettingsWindow closes the settings window without discarding the
+// SettingsWindow itself, so reopening it keeps the last-selected tab/row.
+func (ui *MainUI) HideSettingsWindow() {
+	if ui.Settings == nil || !ui.Settings.Visible {
+		return
+	}
+	ui.Settings.Visible = false
+	ui.Canvas.RemoveWindow(SettingsWindowTitle)
+}
+
+// RefreshSettingsWindow re-renders the settings window's content
+func (ui *MainUI) RefreshSettingsWindow() {
+	if ui.Settings != nil && ui.Settings.Visible {
+		ui.Canvas.UpdateWindowContent(SettingsWindowTitle, ui.Settings.Render())
+	}
+}
+
+// NextTab / PrevTab switch the active tab, resetting the row selection
+func (sw *SettingsWindow) NextTab() {
+	sw.ActiveTab = (sw.ActiveTab + 1) % len(sw.Tabs)
+	sw.Selected = 0
+	sw.Recording = false
+}
+
+func (sw *SettingsWindow) PrevTab() {
+	sw.ActiveTab = (sw.ActiveTab - 1 + len(sw.Tabs)) % len(sw.Tabs)
+	sw.Selected = 0
+	sw.Recording = false
+}
+
+// rowCount returns how many selectable rows the active tab has
+func (sw *SettingsWindow) rowCount() int {
+	if sw.Tabs[sw.ActiveTab] == "Controls" {
+		return len(sw.Rebinds)
+	}
+	return len(sw.Frames[sw.Tabs[sw.ActiveTab]].Controls)
+}
+
+// MoveSelection moves the selected row by delta, clamped to the tab's rows
+func (sw *SettingsWindow) MoveSelection(delta int) {
+	n := sw.rowCount()
+	if n == 0 {
+		return
+	}
+	sw.Selected += delta
+	if sw.Selected < 0 {
+		sw.Selected = 0
+	}
+	if sw.Selected >= n {
+		sw.Selected = n - 1
+	}
+}
+
+// Activate acts on the selected row the way pressing Enter/Space on it
+// would: flips a checkbox, cycles a combobox/color picker forward, or (on
+// the Controls tab) starts capturing a new key for the selected binding.
+func (sw *SettingsWindow) Activate() {
+	if sw.Tabs[sw.ActiveTab] == "Controls" {
+		sw.Recording = true
+		return
+	}
+
+	controls := sw.Frames[sw.Tabs[sw.ActiveTab]].Controls
+	if sw.Selected >= len(controls) {
+		return
+	}
+	c := &controls[sw.Selected]
+
+	switch c.Kind {
+	case ControlCheckbox:
+		if c.Bool != nil {
+			*c.Bool = !*c.Bool
+		}
+	case ControlSpinner:
+		if c.Int != nil {
+			*c.Int++
+		}
+	case ControlCombobox, ControlColorPicker:
+		cycleOption(c, 1)
+	}
+
+	if c.OnApply != nil {
+		c.OnApply()
+	}
+}
+
+// cycleOption advances a combobox/color-picker control to the next (or,
+// with a negative delta, previous) entry in its Options, wrapping around.
+func cycleOption(c *Control, delta int) {
+	if c.Str == nil || len(c.Options) == 0 {
+		return
+	}
+	idx := 0
+	for i, opt := range c.Options {
+		if opt == *c.Str {
+			idx = i
+			break
+		}
+	}
+	idx = (idx + delta + len(c.Options)) % len(c.Options)
+	*c.Str = c.Options[idx]
+}
+
+// CaptureKey finishes a "record key" rebind started by Activate: key
+// becomes the selected action's new binding, or clears it when key is
+// empty. It's a no-op if nothing is being recorded.
+func (sw *SettingsWindow) CaptureKey(key string) {
+	if !sw.Recording || sw.Selected >= len(sw.Rebinds) {
+		return
+	}
+	sw.Recording = false
+
+	action := sw.Rebinds[sw.Selected].Action
+	if key == "" {
+		sw.km.RemoveKeybinding(action)
+	} else {
+		sw.km.Bind(key, action)
+	}
+	sw.Rebinds[sw.Selected].Key = key
+}
+
+// Render builds the settings window's textual content: a tab header row
+// followed by the active tab's rows, the selected one marked with "> ".
+func (sw *SettingsWindow) Render() string {
+	var b strings.Builder
+
+	for i, tab := range sw.Tabs {
+		if i > 0 {
+			b.WriteString(" | ")
+		}
+		if i == sw.ActiveTab {
+			b.WriteString("[" + tab + "]")
+		} else {
+			b.WriteString(tab)
+		}
+	}
+	b.WriteString("\n\n")
+
+	if sw.Tabs[sw.ActiveTab] == "Controls" {
+		for i, entry := range sw.Rebinds {
+			marker := "  "
+			if i == sw.Selected {
+				marker = "> "
+			}
+			key := entry.Key
+			if key == "" {
+				key = "(unbound)"
+			}
+			if i == sw.Selected && sw.Recording {
+				b.WriteString(fmt.Sprintf("%s%-20s %s [press a key, blank clears, esc cancels]\n", marker, entry.Action, key))
+			} else {
+				b.WriteString(fmt.Sprintf("%s%-20s %s\n", marker, entry.Action, key))
+			}
+		}
+		return b.String()
+	}
+
+	for i, c := range sw.Frames[sw.Tabs[sw.ActiveTab]].Controls {
+		marker := "  "
+		if i == sw.Selected {
+			marker = "> "
+		}
+		b.WriteString(marker)
+		b.WriteString(c.Label)
+		b.WriteString(": ")
+		switch c.Kind {
+		case ControlCheckbox:
+			if c.Bool != nil && *c.Bool {
+				b.WriteString("[x]")
+			} else {
+				b.WriteString("[ ]")
+			}
+		case ControlSpinner:
+			if c.Int != nil {
+				fmt.Fprintf(&b, "%d", *c.Int)
+			}
+		case ControlCombobox, ControlColorPicker:
+			if c.Str != nil {
+				b.WriteString(*c.Str)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,308 @@
+/*
+Supervisor turns MenuBar/Toolbar from a flat, always-visible list of
+labeled shortcuts into a real cascading popup menu: clicking a MenuButton
+or a Toolbar button with a Menu opens a stack of MenuItem rows rendered
+as a Canvas window, the same way FindBar/Palette/Settings/HintBar already
+float their own windows. While a popup is open, Supervisor owns mouse and
+key navigation - a click or Enter on a row either dispatches its Action or
+descends into its Submenu, Esc/a click outside steps back up (or closes
+the topmost level), and Up/Down move the selection.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PopupWindowTitle is the canvas window title used for an open menu popup
+const PopupWindowTitle = "Menu Popup"
+
+// MenuButton is a clickable label in the MenuBar that opens a popup of
+// Items when clicked - the cascading replacement for the old flat list
+// of always-visible MenuBar.Items.
+type MenuButton struct {
+	Label string
+	Items []MenuItem
+}
+
+// PopupLevel is one level of an open, possibly nested, popup menu: Items
+// is the list being shown, Selected is the highlighted row, and X/Y is
+// where this level's window is anchored.
+type PopupLevel struct {
+	Items    []MenuItem
+	X, Y     int
+	Selected int
+}
+
+// Supervisor tracks the stack of currently-open popup levels. Stack is
+// empty when nothing is open; the last entry is the level currently being
+// navigated. Opening a submenu pushes a new level; Back pops one.
+type Supervisor struct {
+	Stack []PopupLevel
+}
+
+// Open replaces whatever is open with a fresh top-level popup at (x, y).
+func (s *Supervisor) Open(items []MenuItem, x, y int) {
+	s.Stack = []PopupLevel{{Items: items, X: x, Y: y}}
+}
+
+// IsOpen reports whether any popup level is currently open.
+func (s *Supervisor) IsOpen() bool {
+	return len(s.Stack) > 0
+}
+
+// Close discards the entire popup stack.
+func (s *Supervisor) Close() {
+	s.Stack = nil
+}
+
+// Current returns the topmost (navigable) popup level, or nil if closed.
+func (s *Supervisor) Current() *PopupLevel {
+	if len(s.Stack) == 0 {
+		return nil
+	}
+	return &s.Stack[len(s.Stack)-1]
+}
+
+// Back steps out of a submenu back to its parent level, or closes the
+// popup entirely if there's no parent - what Esc and an outside click do.
+func (s *Supervisor) Back() {
+	if len(s.Stack) > 1 {
+		s.Stack = s.Stack[:len(s.Stack)-1]
+		return
+	}
+	s.Close()
+}
+
+// MoveSelection moves the current level's selection by delta, wrapping
+// around and skipping over separator rows.
+func (s *Supervisor) MoveSelection(delta int) {
+	level := s.Current()
+	if level == nil || len(level.Items) == 0 {
+		return
+	}
+	n := len(level.Items)
+	for i := 0; i < n; i++ {
+		level.Selected = (level.Selected + delta + n) % n
+		if !level.Items[level.Selected].Separator {
+			return
+		}
+	}
+}
+
+// Activate acts on the current level's selected row: if it has a
+// Submenu, a new level is pushed and "" is returned with popup left open;
+// otherwise the popup is closed and the row's Action is returned for the
+// caller to dispatch.
+func (s *Supervisor) Activate() string {
+	level := s.Current()
+	if level == nil || level.Selected >= len(level.Items) {
+		return ""
+	}
+	item := level.Items[level.Selected]
+	if item.Separator {
+		return ""
+	}
+	if len(item.Submenu) > 0 {
+		s.Stack = append(s.Stack, PopupLevel{Items: item.Submenu, X: level.X + 20, Y: level.Y + level.Selected})
+		return ""
+	}
+	s.Close()
+	return item.Action
+}
+
+// OpenMenu opens a fresh popup at (x, y) and shows it on the canvas.
+func (ui *MainUI) OpenMenu(items []MenuItem, x, y int) {
+	ui.Supervisor.Open(items, x, y)
+	ui.refreshPopup()
+}
+
+// CloseMenu discards the open popup, if any.
+func (ui *MainUI) CloseMenu() {
+	if !ui.Supervisor.IsOpen() {
+		return
+	}
+	ui.Supervisor.Close()
+	ui.refreshPopup()
+}
+
+// MenuBack steps the open popup back to its parent level, or closes it.
+func (ui *MainUI) MenuBack() {
+	ui.Supervisor.Back()
+	ui.refreshPopup()
+}
+
+// MenuMoveSelection moves the open popup's selection by delta.
+func (ui *MainUI) MenuMoveSelection(delta int) {
+	ui.Supervisor.MoveSelection(delta)
+	ui.refreshPopup()
+}
+
+// MenuActivate acts on the open popup's selected row, returning an action
+// for the caller to dispatch via HandleInput if one resulted (a leaf was
+// chosen rather than a submenu being entered).
+func (ui *MainUI) MenuActivate() string {
+	action := ui.Supervisor.Activate()
+	ui.refreshPopup()
+	return action
+}
+
+// refreshPopup adds, moves/repaints, or removes the Canvas window backing
+// the open popup so it always matches Supervisor's current state.
+func (ui *MainUI) refreshPopup() {
+	level := ui.Supervisor.Current()
+	if level == nil {
+		ui.Canvas.RemoveWindow(PopupWindowTitle)
+		return
+	}
+
+	width := popupWidth(level.Items)
+	height := len(level.Items) + 2
+	content := ui.renderPopup()
+
+	if ui.Canvas.FindWindow(PopupWindowTitle) == nil {
+		ui.Canvas.AddWindow(Window{
+			Title:      PopupWindowTitle,
+			X:          level.X,
+			Y:          level.Y,
+			Width:      width,
+			Height:     height,
+			Content:    content,
+			Resizable:  false,
+			WindowType: "popup",
+		})
+		return
+	}
+
+	ui.Canvas.SetWindowRect(PopupWindowTitle, level.X, level.Y, width, height)
+	ui.Canvas.UpdateWindowContent(PopupWindowTitle, content)
+}
+
+// popupWidth sizes a popup wide enough for its longest row plus padding.
+func popupWidth(items []MenuItem) int {
+	width := 16
+	for _, item := range items {
+		w := len(item.Label) + 4
+		if item.Key != "" {
+			w += len(item.Key) + 3
+		}
+		if w > width {
+			width = w
+		}
+	}
+	return width
+}
+
+// renderPopup builds the open popup's textual content: one row per item,
+// the selected row marked with "> ", separators drawn as a divider, and
+// items with a Submenu suffixed with " >" to hint they cascade further.
+func (ui *MainUI) renderPopup() string {
+	level := ui.Supervisor.Current()
+	if level == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, item := range level.Items {
+		if item.Separator {
+			b.WriteString("  ----------------\n")
+			continue
+		}
+
+		marker := "  "
+		if i == level.Selected {
+			marker = "> "
+		}
+
+		label := item.Label
+		if len(item.Submenu) > 0 {
+			label += " >"
+		}
+
+		if item.Key != "" {
+			fmt.Fprintf(&b, "%s%-12s %s\n", marker, label, item.Key)
+		} else {
+			fmt.Fprintf(&b, "%s%s\n", marker, label)
+		}
+	}
+	return b.String()
+}
+
+// PopupRowAt returns which row of the open popup a click at (x, y) landed
+// on, given the popup window's current on-screen rect.
+func (ui *MainUI) PopupRowAt(x, y int) (int, bool) {
+	win := ui.Canvas.FindWindow(PopupWindowTitle)
+	level := ui.Supervisor.Current()
+	if win == nil || level == nil {
+		return 0, false
+	}
+	if x < win.X || x >= win.X+win.Width || y < win.Y || y >= win.Y+win.Height {
+		return 0, false
+	}
+	row := y - win.Y - 1 // account for the window's top border row
+	if row < 0 || row >= len(level.Items) {
+		return 0, false
+	}
+	return row, true
+}
+
+// menuButtonAt returns the MenuButton a click at (x, y) landed on, if the
+// click is within the Menu Bar window's rows. There is currently a single
+// "File" button spanning the whole bar, so any click on the bar opens it.
+func (ui *MainUI) menuButtonAt(x, y int) (*MenuButton, int, int, bool) {
+	win := ui.Canvas.FindWindow("Menu Bar")
+	if win == nil || len(ui.MenuBar.Buttons) == 0 {
+		return nil, 0, 0, false
+	}
+	if x < win.X || x >= win.X+win.Width || y < win.Y || y >= win.Y+win.Height {
+		return nil, 0, 0, false
+	}
+	return &ui.MenuBar.Buttons[0], win.X, win.Y + win.Height, true
+}
+
+// toolbarButtonAt returns the ToolbarButton a click at (x, y) landed on,
+// if any - only buttons with a non-empty Menu open a popup on click.
+func (ui *MainUI) toolbarButtonAt(x, y int) (*ToolbarButton, int, int, bool) {
+	win := ui.Canvas.FindWindow("Toolbar")
+	if win == nil || y < win.Y || y >= win.Y+win.Height {
+		return nil, 0, 0, false
+	}
+	relX := x - win.X
+	for i := range ui.Toolbar.Buttons {
+		button := &ui.Toolbar.Buttons[i]
+		if len(button.Menu) == 0 {
+			continue
+		}
+		if relX >= button.X && relX < button.X+button.Width {
+			return button, win.X + button.X, win.Y + win.Height, true
+		}
+	}
+	return nil, 0, 0, false
+}
+
+// HandleMouseClick is the single entry point for a left-click anywhere in
+// the editor: it routes the click to the open popup (dispatch-on-inside,
+// close-on-outside) or, with nothing open, to whichever MenuBar/Toolbar
+// button the click landed on.
+func (ui *MainUI) HandleMouseClick(x, y int) (action string) {
+	if ui.Supervisor.IsOpen() {
+		if row, ok := ui.PopupRowAt(x, y); ok {
+			ui.Supervisor.Current().Selected = row
+			return ui.MenuActivate()
+		}
+		ui.CloseMenu()
+		return ""
+	}
+
+	if btn, bx, by, ok := ui.menuButtonAt(x, y); ok {
+		ui.OpenMenu(btn.Items, bx, by)
+		return ""
+	}
+	if btn, bx, by, ok := ui.toolbarButtonAt(x, y); ok {
+		ui.OpenMenu(btn.Menu, bx, by)
+		return ""
+	}
+	return ""
+}
@@ -4,6 +4,14 @@ and add a theme managfer in the future */
 
 package ui
 
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
 // Theme holds all color codes for UI elements
 // This makes it easy to change the color scheme and add a theme manager
 //
@@ -15,11 +23,84 @@ type Theme struct {
 	StatusBarColor  string
 	TextAreaColor   string
 	NumberLineColor string
+	KeywordColor    string
+	StringColor     string
+	CommentColor    string
+	NumberColor     string
 	// Add more as needed
 }
 
-// ThemeManager will manage switching and loading themes (future)
+// ColorFor resolves a syntax.Span color name (e.g. "keyword") to the hex
+// code configured for it, falling back to ForegroundColor for unknown
+// names so an unrecognized language config just renders as plain text.
+func (t *Theme) ColorFor(name string) string {
+	switch name {
+	case "keyword":
+		return t.KeywordColor
+	case "string":
+		return t.StringColor
+	case "comment":
+		return t.CommentColor
+	case "number":
+		return t.NumberColor
+	case "accent":
+		return t.AccentColor
+	default:
+		return t.ForegroundColor
+	}
+}
+
+// ThemeManager manages the active theme and its persistence
 type ThemeManager struct {
 	Current Theme
-	// Placeholder for theme management logic
+}
+
+// DefaultThemePath returns the per-user theme file location, alongside
+// the keybindings and history files under ~/.config/letsgo-editor.
+func DefaultThemePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error resolving home directory: %v", err)
+	}
+	return filepath.Join(home, ".config", "letsgo-editor", "theme.json"), nil
+}
+
+// Save writes the current theme to filename as JSON, creating its parent
+// directory if needed.
+func (tm *ThemeManager) Save(filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("error creating theme directory: %v", err)
+	}
+
+	data, err := json.MarshalIndent(tm.Current, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling theme: %v", err)
+	}
+
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("error writing theme file: %v", err)
+	}
+	return nil
+}
+
+// Load replaces the current theme with the contents of filename. A
+// missing file is not an error; it just leaves Current as it was, the way
+// a first run has no saved theme yet.
+func (tm *ThemeManager) Load(filename string) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("error reading theme file: %v", err)
+	}
+
+	var theme Theme
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return fmt.Errorf("error parsing theme file: %v", err)
+	}
+
+	tm.Current = theme
+	return nil
 }
@@ -15,16 +15,25 @@ package ui
 import (
 	"fmt"
 	"strings"
+
+	"github.com/gdamore/tcell/v2"
+
+	"letsgo-editor/syntax"
 )
 
 // Canvas is the central UI area for the editor
 // All UI elements are rendered here
 // Supports multiple resizable windows and lightweight rendering
 //
+// Screen is nil until InitScreen succeeds. Render falls back to the
+// original line-printed layout when Screen is nil, so the canvas still
+// works when stdout isn't a real terminal (piped input, tests, etc.).
 type Canvas struct {
 	Windows []Window // All open windows/scripts
 	Width   int      // Canvas width
 	Height  int      // Canvas height
+	Screen  tcell.Screen // real terminal screen, set by InitScreen
+	Theme   *Theme       // colors used when drawing onto Screen
 }
 
 // Window represents a resizable UI window on the canvas
@@ -38,6 +47,10 @@ type Window struct {
 	Visible  bool   // Whether window is visible
 	Resizable bool  // Whether window can be resized
 	WindowType string // Type: "editor", "menu", "toolbar", "status", etc.
+
+	// Highlights holds per-line syntax spans for "editor" windows,
+	// indexed the same as the lines in Content. nil disables colorizing.
+	Highlights [][]syntax.Span
 }
 
 // NewCanvas creates a new canvas with default dimensions
@@ -49,6 +62,46 @@ func NewCanvas(width, height int) *Canvas {
 	}
 }
 
+// InitScreen takes over the terminal: it allocates a tcell.Screen, enters
+// the alternate screen / raw mode, and syncs Width/Height to the real
+// terminal size. Callers must invoke CloseScreen (typically via defer)
+// before the process exits so the terminal is restored.
+func (c *Canvas) InitScreen() error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return fmt.Errorf("error creating screen: %v", err)
+	}
+
+	if err := screen.Init(); err != nil {
+		return fmt.Errorf("error initializing screen: %v", err)
+	}
+
+	screen.EnableMouse()
+	screen.Clear()
+
+	c.Screen = screen
+	c.SyncSize()
+	return nil
+}
+
+// CloseScreen restores the terminal to its original state
+func (c *Canvas) CloseScreen() {
+	if c.Screen == nil {
+		return
+	}
+	c.Screen.Fini()
+	c.Screen = nil
+}
+
+// SyncSize refreshes Width/Height from the real terminal size, e.g. in
+// response to a resize event
+func (c *Canvas) SyncSize() {
+	if c.Screen == nil {
+		return
+	}
+	c.Width, c.Height = c.Screen.Size()
+}
+
 // AddWindow adds a new window to the canvas
 func (c *Canvas) AddWindow(window Window) {
 	window.Visible = true
@@ -131,6 +184,33 @@ func (c *Canvas) MoveWindow(title string, x, y int) bool {
 	return true
 }
 
+// SetWindowRect directly sets a window's position and size, bypassing the
+// Resizable check ResizeWindow enforces for interactive use - a pane
+// manager recomputing the whole dock layout isn't a user drag, and needs
+// to reposition every pane regardless of whether it's individually
+// resizable.
+func (c *Canvas) SetWindowRect(title string, x, y, width, height int) bool {
+	window := c.FindWindow(title)
+	if window == nil {
+		return false
+	}
+
+	window.X, window.Y, window.Width, window.Height = x, y, width, height
+	return true
+}
+
+// SetWindowVisible sets a window's visibility directly, unlike
+// ToggleWindowVisibility which always flips it.
+func (c *Canvas) SetWindowVisible(title string, visible bool) bool {
+	window := c.FindWindow(title)
+	if window == nil {
+		return false
+	}
+
+	window.Visible = visible
+	return true
+}
+
 // ToggleWindowVisibility toggles a window's visibility
 func (c *Canvas) ToggleWindowVisibility(title string) bool {
 	window := c.FindWindow(title)
@@ -149,32 +229,63 @@ func (c *Canvas) UpdateWindowContent(title, content string) bool {
 	if window == nil {
 		return false
 	}
-	
+
 	window.Content = content
 	return true
 }
 
-// Render draws all windows/scripts on the canvas
+// UpdateWindowHighlights sets the per-line syntax spans used to colorize
+// a window's content when drawing onto Screen
+func (c *Canvas) UpdateWindowHighlights(title string, highlights [][]syntax.Span) bool {
+	window := c.FindWindow(title)
+	if window == nil {
+		return false
+	}
+
+	window.Highlights = highlights
+	return true
+}
+
+// Render draws all windows/scripts on the canvas. When Screen is set
+// (InitScreen succeeded) it draws into the real terminal cell grid and
+// flushes with a single Show per frame; otherwise it falls back to the
+// original line-printed rendering.
 func (c *Canvas) Render() {
-	fmt.Printf("\n=== Canvas (%dx%d) ===\n", c.Width, c.Height)
-	
 	if len(c.Windows) == 0 {
-		fmt.Println("No windows open")
+		if c.Screen == nil {
+			fmt.Printf("\n=== Canvas (%dx%d) ===\n", c.Width, c.Height)
+			fmt.Println("No windows open")
+		}
 		return
 	}
-	
-	// Sort windows by type for consistent rendering order
+
+	for _, window := range c.orderedVisibleWindows() {
+		if c.Screen != nil {
+			c.drawWindow(window)
+		} else {
+			c.renderWindow(window)
+		}
+	}
+
+	if c.Screen != nil {
+		c.Screen.Show()
+	}
+}
+
+// orderedVisibleWindows returns the visible windows in the fixed
+// rendering order: menu, toolbar, editor, other, status
+func (c *Canvas) orderedVisibleWindows() []Window {
 	menuWindows := []Window{}
 	toolbarWindows := []Window{}
 	editorWindows := []Window{}
 	statusWindows := []Window{}
 	otherWindows := []Window{}
-	
+
 	for _, window := range c.Windows {
 		if !window.Visible {
 			continue
 		}
-		
+
 		switch window.WindowType {
 		case "menu":
 			menuWindows = append(menuWindows, window)
@@ -188,16 +299,96 @@ func (c *Canvas) Render() {
 			otherWindows = append(otherWindows, window)
 		}
 	}
-	
-	// Render in order: menu, toolbar, editor, other, status
+
 	allWindows := append(menuWindows, toolbarWindows...)
 	allWindows = append(allWindows, editorWindows...)
 	allWindows = append(allWindows, otherWindows...)
 	allWindows = append(allWindows, statusWindows...)
-	
-	for _, window := range allWindows {
-		c.renderWindow(window)
+	return allWindows
+}
+
+// drawWindow draws a single window's border and content into the
+// Screen's cell grid, honoring the window's X/Y/Width/Height and the
+// canvas Theme's colors. tcell.Screen.Show diffs the whole frame against
+// the last one internally, so a straightforward full redraw here is
+// already a diffed update in practice.
+func (c *Canvas) drawWindow(window Window) {
+	style := tcell.StyleDefault
+	if c.Theme != nil {
+		style = style.
+			Background(tcell.GetColor(c.Theme.BackgroundColor)).
+			Foreground(tcell.GetColor(c.Theme.ForegroundColor))
+	}
+
+	x0, y0 := window.X, window.Y
+	x1, y1 := x0+window.Width-1, y0+window.Height-1
+	if x1 < x0 || y1 < y0 {
+		return
+	}
+
+	// Border
+	for x := x0 + 1; x < x1; x++ {
+		c.Screen.SetContent(x, y0, tcell.RuneHLine, nil, style)
+		c.Screen.SetContent(x, y1, tcell.RuneHLine, nil, style)
+	}
+	for y := y0 + 1; y < y1; y++ {
+		c.Screen.SetContent(x0, y, tcell.RuneVLine, nil, style)
+		c.Screen.SetContent(x1, y, tcell.RuneVLine, nil, style)
+	}
+	c.Screen.SetContent(x0, y0, tcell.RuneULCorner, nil, style)
+	c.Screen.SetContent(x1, y0, tcell.RuneURCorner, nil, style)
+	c.Screen.SetContent(x0, y1, tcell.RuneLLCorner, nil, style)
+	c.Screen.SetContent(x1, y1, tcell.RuneLRCorner, nil, style)
+
+	titleStyle := style
+	if c.Theme != nil {
+		titleStyle = style.Foreground(tcell.GetColor(c.Theme.AccentColor))
+	}
+	for i, r := range " " + window.Title + " " {
+		if x0+1+i >= x1 {
+			break
+		}
+		c.Screen.SetContent(x0+1+i, y0, r, nil, titleStyle)
+	}
+
+	innerWidth := window.Width - 2
+	innerHeight := window.Height - 2
+	if innerWidth < 1 || innerHeight < 1 {
+		return
+	}
+
+	lines := strings.Split(window.Content, "\n")
+	for row := 0; row < innerHeight; row++ {
+		if row >= len(lines) {
+			break
+		}
+
+		var spans []syntax.Span
+		if row < len(window.Highlights) {
+			spans = window.Highlights[row]
+		}
+
+		for col, r := range lines[row] {
+			if col >= innerWidth {
+				break
+			}
+			c.Screen.SetContent(x0+1+col, y0+1+row, r, nil, styleFor(style, c.Theme, spans, col))
+		}
+	}
+}
+
+// styleFor returns base unless col falls within one of spans, in which
+// case it's recolored with the theme's color for that span
+func styleFor(base tcell.Style, theme *Theme, spans []syntax.Span, col int) tcell.Style {
+	if theme == nil {
+		return base
+	}
+	for _, span := range spans {
+		if col >= span.Start && col < span.End {
+			return base.Foreground(tcell.GetColor(theme.ColorFor(span.Color)))
+		}
 	}
+	return base
 }
 
 // renderWindow renders a single window